@@ -24,9 +24,10 @@ type Config struct {
 	// PluginManager configuration information.
 	PluginManager struct {
 		// Library is the path where plugin directories containing plugin files are present.
-		Library string `yaml:"library"`
-		LogDir  string `yaml:"log dir"`
-		LogFile string `yaml:"log file"`
+		Library  string `yaml:"library"`
+		LogDir   string `yaml:"log dir"`
+		LogFile  string `yaml:"log file"`
+		StateDir string `yaml:"state dir"`
 	}
 }
 
@@ -56,7 +57,7 @@ func saveConfig(newConfig Config, configFile string) error {
 	return nil
 }
 
-func setIntegrationEnvironment(topPath string) string {
+func setIntegrationEnvironment(topPath, stateDir string) string {
 	logger.Info.Println("Entering setIntegrationEnvironment")
 	defer logger.Info.Println("Exiting setIntegrationEnvironment")
 
@@ -66,6 +67,7 @@ func setIntegrationEnvironment(topPath string) string {
 	newConfig.PluginManager.Library = filepath.FromSlash(topPath + "/sample/library")
 	newConfig.PluginManager.LogDir = filepath.FromSlash(topPath)
 	newConfig.PluginManager.LogFile = "pm-integ"
+	newConfig.PluginManager.StateDir = stateDir
 
 	saveConfig(newConfig, configFile)
 	os.Setenv(config.EnvConfFile, configFile)
@@ -115,7 +117,7 @@ func TestIntegration(t *testing.T) {
 	}
 
 	oriConfigFile := os.Getenv(config.EnvConfFile)
-	configFile := setIntegrationEnvironment(tDir)
+	configFile := setIntegrationEnvironment(tDir, t.TempDir())
 	defer os.Remove(configFile)
 	defer os.Setenv(config.EnvConfFile, oriConfigFile)
 
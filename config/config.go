@@ -17,7 +17,22 @@ type Config struct {
 	// PluginManager configuration information.
 	PluginManager struct {
 		// Library is the path where plugin directories containing plugin files are present.
-		Library  string `yaml:"library"`
+		Library string `yaml:"library"`
+		// Libraries is the ordered list of plugins libraries i.e., the search
+		// 	path. The plugins in the libraries listed first override the
+		// 	same-named plugins in the libraries listed later.
+		//  When specified, Library is ignored.
+		Libraries []string `yaml:"libraries"`
+		// OverrideLibrary is the path where plugin drop-in override
+		// 	directories (i.e., "<component>/<plugin>.d/*.conf") are present.
+		OverrideLibrary string `yaml:"override library"`
+		// LibraryDepth is the maximum levels of nested component directories
+		// 	in the libraries. Default: 1 i.e., "<component>/<plugin-file>".
+		LibraryDepth int `yaml:"library depth"`
+		// StateDir is the path where Plugin Manager stores the persistent
+		// 	state like the disabled and masked plugins, the last run status
+		// 	of each plugin type, the state of each plugin and the run history.
+		StateDir string `yaml:"state dir"`
 		LogDir   string `yaml:"log dir"`
 		LogFile  string `yaml:"log file"`
 		LogLevel string `yaml:"log level"`
@@ -26,6 +41,9 @@ type Config struct {
 
 var myConfig Config
 
+// defaultStateDir is the state directory used when it's not configured.
+var defaultStateDir = "/var/lib/asum/pm/"
+
 var (
 	// EnvConfFile is environment variable containing the config file path.
 	EnvConfFile string
@@ -54,11 +72,63 @@ func GetLogLevel() string {
 }
 
 // GetPluginsLibrary gets location of plugins library.
+//
+//	NOTE: When the libraries are configured, the highest precedence library
+//	is returned.
 func GetPluginsLibrary() string {
+	if len(myConfig.PluginManager.Libraries) != 0 {
+		return GetPluginsLibraries()[0]
+	}
 	return filepath.FromSlash(filepath.Clean(myConfig.PluginManager.Library) +
 		string(os.PathSeparator))
 }
 
+// GetPluginsLibraries gets the ordered list of plugins libraries, with the
+// highest precedence library first.
+//
+//	NOTE: When the libraries are not configured, the library is returned
+//	as the only library.
+func GetPluginsLibraries() []string {
+	if len(myConfig.PluginManager.Libraries) == 0 {
+		return []string{GetPluginsLibrary()}
+	}
+	libraries := []string{}
+	for _, library := range myConfig.PluginManager.Libraries {
+		libraries = append(libraries, filepath.FromSlash(filepath.Clean(library)+
+			string(os.PathSeparator)))
+	}
+	return libraries
+}
+
+// GetLibraryDepth gets the maximum levels of nested component directories in
+// the plugins libraries.
+func GetLibraryDepth() int {
+	if myConfig.PluginManager.LibraryDepth < 1 {
+		return 1
+	}
+	return myConfig.PluginManager.LibraryDepth
+}
+
+// GetStateDir gets the location for storing the Plugin Manager state.
+func GetStateDir() string {
+	stateDir := myConfig.PluginManager.StateDir
+	if stateDir == "" {
+		stateDir = defaultStateDir
+	}
+	return filepath.FromSlash(filepath.Clean(stateDir) + string(os.PathSeparator))
+}
+
+// GetPluginsOverrideLibrary gets location of plugins override library.
+//
+//	NOTE: Returns empty string when the override library is not configured.
+func GetPluginsOverrideLibrary() string {
+	if myConfig.PluginManager.OverrideLibrary == "" {
+		return ""
+	}
+	return filepath.FromSlash(filepath.Clean(myConfig.PluginManager.OverrideLibrary) +
+		string(os.PathSeparator))
+}
+
 // GetPMLogDir provides location for storing Plugin Manager logs.
 //
 //	NOTE: The plugin logs would be stored "plugins" directory under the
@@ -109,7 +179,7 @@ func readConfigFile(confFilePath string) (Config, error) {
 
 	err = yaml.Unmarshal(bFileContents, &conf)
 	if err != nil {
-		logger.Error.Printf("Failed to call yaml.Unmarshal(%s, %s); err=%s",
+		logger.Error.Printf("Failed to call yaml.Unmarshal(%s, %+v); err=%s",
 			bFileContents, &conf, err.Error())
 		return conf, logger.ConsoleError.PrintNReturnError("Failed to parse %s config file.", confFilePath)
 	}
@@ -152,6 +222,33 @@ func SetPluginsLibrary(library string) {
 		filepath.Clean(library) + string(os.PathSeparator))
 }
 
+// SetPluginsLibraries sets the ordered list of plugins libraries, with the
+// highest precedence library first.
+//
+//	NOTE: The first library is also set as the plugins library.
+func SetPluginsLibraries(libraries []string) {
+	myConfig.PluginManager.Libraries = libraries
+	if len(libraries) != 0 {
+		SetPluginsLibrary(libraries[0])
+	}
+}
+
+// SetLibraryDepth sets the maximum levels of nested component directories in
+// the plugins libraries.
+func SetLibraryDepth(depth int) {
+	myConfig.PluginManager.LibraryDepth = depth
+}
+
+// SetStateDir sets the location for storing the Plugin Manager state.
+func SetStateDir(stateDir string) {
+	myConfig.PluginManager.StateDir = stateDir
+}
+
+// SetPluginsOverrideLibrary sets the plugins override library location.
+func SetPluginsOverrideLibrary(library string) {
+	myConfig.PluginManager.OverrideLibrary = library
+}
+
 // SetPMLogFile sets the file for storing Plugin Manager logs.
 func SetPMLogFile(logfile string) {
 	// add .log suffix if it doesn't exist.
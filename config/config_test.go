@@ -68,10 +68,14 @@ func Test_Load(t *testing.T) {
 			},
 			want: Config{
 				PluginManager: struct {
-					Library  string "yaml:\"library\""
-					LogDir   string "yaml:\"log dir\""
-					LogFile  string "yaml:\"log file\""
-					LogLevel string "yaml:\"log level\""
+					Library         string   "yaml:\"library\""
+					Libraries       []string "yaml:\"libraries\""
+					OverrideLibrary string   "yaml:\"override library\""
+					LibraryDepth    int      "yaml:\"library depth\""
+					StateDir        string   "yaml:\"state dir\""
+					LogDir          string   "yaml:\"log dir\""
+					LogFile         string   "yaml:\"log file\""
+					LogLevel        string   "yaml:\"log level\""
 				}{
 					Library:  "../sample/library",
 					LogDir:   "./",
@@ -133,10 +137,14 @@ func Test_readConfigFile(t *testing.T) {
 			},
 			want: Config{
 				PluginManager: struct {
-					Library  string "yaml:\"library\""
-					LogDir   string "yaml:\"log dir\""
-					LogFile  string "yaml:\"log file\""
-					LogLevel string "yaml:\"log level\""
+					Library         string   "yaml:\"library\""
+					Libraries       []string "yaml:\"libraries\""
+					OverrideLibrary string   "yaml:\"override library\""
+					LibraryDepth    int      "yaml:\"library depth\""
+					StateDir        string   "yaml:\"state dir\""
+					LogDir          string   "yaml:\"log dir\""
+					LogFile         string   "yaml:\"log file\""
+					LogLevel        string   "yaml:\"log level\""
 				}{
 					Library:  "../sample/library",
 					LogDir:   "./",
@@ -153,10 +161,14 @@ func Test_readConfigFile(t *testing.T) {
 			},
 			want: Config{
 				PluginManager: struct {
-					Library  string "yaml:\"library\""
-					LogDir   string "yaml:\"log dir\""
-					LogFile  string "yaml:\"log file\""
-					LogLevel string "yaml:\"log level\""
+					Library         string   "yaml:\"library\""
+					Libraries       []string "yaml:\"libraries\""
+					OverrideLibrary string   "yaml:\"override library\""
+					LibraryDepth    int      "yaml:\"library depth\""
+					StateDir        string   "yaml:\"state dir\""
+					LogDir          string   "yaml:\"log dir\""
+					LogFile         string   "yaml:\"log file\""
+					LogLevel        string   "yaml:\"log level\""
 				}{},
 			},
 			wantErr: true,
@@ -168,10 +180,14 @@ func Test_readConfigFile(t *testing.T) {
 			},
 			want: Config{
 				PluginManager: struct {
-					Library  string "yaml:\"library\""
-					LogDir   string "yaml:\"log dir\""
-					LogFile  string "yaml:\"log file\""
-					LogLevel string "yaml:\"log level\""
+					Library         string   "yaml:\"library\""
+					Libraries       []string "yaml:\"libraries\""
+					OverrideLibrary string   "yaml:\"override library\""
+					LibraryDepth    int      "yaml:\"library depth\""
+					StateDir        string   "yaml:\"state dir\""
+					LogDir          string   "yaml:\"log dir\""
+					LogFile         string   "yaml:\"log file\""
+					LogLevel        string   "yaml:\"log level\""
 				}{},
 			},
 			wantErr: true,
@@ -191,3 +207,47 @@ func Test_readConfigFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetPluginsLibraries(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	restoreConfig := myConfig
+	defer func() { myConfig = restoreConfig }()
+
+	tests := []struct {
+		name          string
+		library       string
+		libraries     []string
+		wantLibrary   string
+		wantLibraries []string
+	}{
+		{
+			name:          "Only library",
+			library:       "/opt/plugins",
+			wantLibrary:   "/opt/plugins/",
+			wantLibraries: []string{"/opt/plugins/"},
+		},
+		{
+			name:          "Libraries override library",
+			library:       "/opt/plugins",
+			libraries:     []string{"/etc/plugins", "/opt/plugins/"},
+			wantLibrary:   "/etc/plugins/",
+			wantLibraries: []string{"/etc/plugins/", "/opt/plugins/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			myConfig.PluginManager.Library = tt.library
+			myConfig.PluginManager.Libraries = tt.libraries
+			if got := GetPluginsLibrary(); got != tt.wantLibrary {
+				t.Errorf("GetPluginsLibrary() = %v, want %v", got, tt.wantLibrary)
+			}
+			if got := GetPluginsLibraries(); !reflect.DeepEqual(got, tt.wantLibraries) {
+				t.Errorf("GetPluginsLibraries() = %v, want %v", got, tt.wantLibraries)
+			}
+		})
+	}
+}
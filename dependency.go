@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm dependency is used for diagnosing the plugin dependencies.
+package pm
+
+import (
+	"sort"
+	"strings"
+)
+
+// MissingDependency is a reference to a plugin that is not present.
+type MissingDependency struct {
+	// Plugin is the plugin that references the missing plugin.
+	Plugin string
+	// Requires is the missing plugin.
+	Requires string
+}
+
+// DependencyError is the error returned when plugins cannot be run due to
+// circular dependencies or missing plugins.
+type DependencyError struct {
+	// Cycles are the circular dependencies. Each cycle is a path of plugins
+	// 	where each plugin requires the next one, and the path ends with the
+	// 	first plugin i.e., [A, B, C, A] means A -> B -> C -> A.
+	Cycles [][]string
+	// Missing are the references to plugins that are not present.
+	Missing []MissingDependency
+	// Blocked are the plugins which are neither part of a cycle nor have
+	// 	missing dependencies, but depend on such plugins.
+	Blocked []string
+}
+
+// Error returns the description of all the dependency issues.
+func (e *DependencyError) Error() string {
+	msgs := []string{}
+	for _, cycle := range e.Cycles {
+		msgs = append(msgs, "circular dependency: "+strings.Join(cycle, " -> "))
+	}
+	for _, m := range e.Missing {
+		msgs = append(msgs, "missing dependency: "+m.Plugin+" requires "+m.Requires)
+	}
+	if len(e.Blocked) != 0 {
+		msgs = append(msgs, "blocked by above dependencies: "+strings.Join(e.Blocked, ", "))
+	}
+	return "Plugins have dependency issues:\n\t" + strings.Join(msgs, "\n\t")
+}
+
+// getCyclePlugins returns the plugins that are part of any cycle.
+func (e *DependencyError) getCyclePlugins() map[string]bool {
+	plugins := map[string]bool{}
+	for _, cycle := range e.Cycles {
+		for _, p := range cycle {
+			plugins[p] = true
+		}
+	}
+	return plugins
+}
+
+// diagnoseDependencies finds the circular dependencies and missing plugins
+// due to which the specified notPlacedPlugins cannot be run.
+//
+//	NOTE: nPInfo must be in normalized form (i.e., normalizePluginsInfo()).
+func diagnoseDependencies(nPInfo Plugins, notPlacedPlugins []string) *DependencyError {
+	depErr := &DependencyError{}
+	requires := map[string][]string{}
+	for _, pInfo := range nPInfo {
+		requires[pInfo.Name] = pInfo.Requires
+	}
+
+	names := []string{}
+	for _, pInfo := range nPInfo {
+		names = append(names, pInfo.Name)
+	}
+	sort.Strings(names)
+
+	problematic := map[string]bool{}
+	for _, p := range names {
+		for _, rs := range requires[p] {
+			if _, ok := requires[rs]; !ok && !isPluginRef(rs) {
+				depErr.Missing = append(depErr.Missing, MissingDependency{Plugin: p, Requires: rs})
+				problematic[p] = true
+			}
+		}
+	}
+
+	for _, scc := range getStronglyConnectedComponents(names, requires) {
+		if len(scc) == 1 && !containsString(requires[scc[0]], scc[0]) {
+			continue
+		}
+		depErr.Cycles = append(depErr.Cycles, getCyclePath(scc, requires))
+		for _, p := range scc {
+			problematic[p] = true
+		}
+	}
+
+	for _, p := range notPlacedPlugins {
+		if !problematic[p] && !containsString(depErr.Blocked, p) {
+			depErr.Blocked = append(depErr.Blocked, p)
+		}
+	}
+	sort.Strings(depErr.Blocked)
+	return depErr
+}
+
+// getStronglyConnectedComponents returns the strongly connected components of
+// the plugins graph using Tarjan's algorithm. Each component is sorted, and
+// the components are ordered by their first plugin.
+func getStronglyConnectedComponents(names []string, requires map[string][]string) [][]string {
+	index := 0
+	indexes := map[string]int{}
+	lowLinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	sccs := [][]string{}
+
+	var strongConnect func(p string)
+	strongConnect = func(p string) {
+		indexes[p] = index
+		lowLinks[p] = index
+		index++
+		stack = append(stack, p)
+		onStack[p] = true
+
+		for _, rs := range requires[p] {
+			if _, ok := requires[rs]; !ok {
+				// Missing plugin.
+				continue
+			}
+			if _, visited := indexes[rs]; !visited {
+				strongConnect(rs)
+				if lowLinks[rs] < lowLinks[p] {
+					lowLinks[p] = lowLinks[rs]
+				}
+			} else if onStack[rs] && indexes[rs] < lowLinks[p] {
+				lowLinks[p] = indexes[rs]
+			}
+		}
+
+		if lowLinks[p] == indexes[p] {
+			scc := []string{}
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				scc = append(scc, top)
+				if top == p {
+					break
+				}
+			}
+			sort.Strings(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, p := range names {
+		if _, visited := indexes[p]; !visited {
+			strongConnect(p)
+		}
+	}
+	sort.Slice(sccs, func(i, j int) bool { return sccs[i][0] < sccs[j][0] })
+	return sccs
+}
+
+// getCyclePath returns the shortest cycle path starting and ending at the
+// first plugin of the specified strongly connected component.
+func getCyclePath(scc []string, requires map[string][]string) []string {
+	start := scc[0]
+	inScc := map[string]bool{}
+	for _, p := range scc {
+		inScc[p] = true
+	}
+	// INFO: Breadth first search from start plugin until start is reached
+	// 	again, and then trace back the path using the parent links.
+	parent := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, rs := range requires[p] {
+			if !inScc[rs] {
+				continue
+			}
+			if rs == start {
+				path := []string{start}
+				for n := p; n != start; n = parent[n] {
+					path = append([]string{n}, path...)
+				}
+				return append([]string{start}, path...)
+			}
+			if _, seen := parent[rs]; !seen {
+				parent[rs] = p
+				queue = append(queue, rs)
+			}
+		}
+	}
+	return scc
+}
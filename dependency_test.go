@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_validateDependencies_diagnostics(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name        string
+		pluginsInfo Plugins
+		want        *DependencyError
+		wantMsg     string
+	}{
+		{
+			name: "Indirect circular dependency with a blocked plugin",
+			pluginsInfo: Plugins{
+				{Name: "A/a.test", Requires: []string{"B/b.test"}},
+				{Name: "B/b.test", Requires: []string{"C/c.test"}},
+				{Name: "C/c.test", Requires: []string{"A/a.test"}},
+				{Name: "D/d.test", Requires: []string{"C/c.test"}},
+				{Name: "E/e.test"},
+			},
+			want: &DependencyError{
+				Cycles:  [][]string{{"A/a.test", "B/b.test", "C/c.test", "A/a.test"}},
+				Blocked: []string{"D/d.test"},
+			},
+			wantMsg: "Plugins have dependency issues:\n" +
+				"\tcircular dependency: A/a.test -> B/b.test -> C/c.test -> A/a.test\n" +
+				"\tblocked by above dependencies: D/d.test",
+		},
+		{
+			name: "Self dependency",
+			pluginsInfo: Plugins{
+				{Name: "A/a.test", Requires: []string{"A/a.test"}},
+			},
+			want: &DependencyError{
+				Cycles: [][]string{{"A/a.test", "A/a.test"}},
+			},
+			wantMsg: "Plugins have dependency issues:\n" +
+				"\tcircular dependency: A/a.test -> A/a.test",
+		},
+		{
+			name: "Missing dependency with a blocked plugin",
+			pluginsInfo: Plugins{
+				{Name: "A/a.test", Requires: []string{"X/x.test"}},
+				{Name: "B/b.test", Requires: []string{"A/a.test"}},
+			},
+			want: &DependencyError{
+				Missing: []MissingDependency{{Plugin: "A/a.test", Requires: "X/x.test"}},
+				Blocked: []string{"B/b.test"},
+			},
+			wantMsg: "Plugins have dependency issues:\n" +
+				"\tmissing dependency: A/a.test requires X/x.test\n" +
+				"\tblocked by above dependencies: B/b.test",
+		},
+		{
+			name: "Cycle and missing dependency",
+			pluginsInfo: Plugins{
+				{Name: "A/a.test", Requires: []string{"B/b.test"}},
+				{Name: "B/b.test", RequiredBy: []string{"A/a.test"}, Requires: []string{"A/a.test"}},
+				{Name: "C/c.test", RequiredBy: []string{"X/x.test"}},
+			},
+			want: &DependencyError{
+				Cycles:  [][]string{{"A/a.test", "B/b.test", "A/a.test"}},
+				Missing: []MissingDependency{{Plugin: "C/c.test", Requires: "X/x.test"}},
+			},
+			wantMsg: "Plugins have dependency issues:\n" +
+				"\tcircular dependency: A/a.test -> B/b.test -> A/a.test\n" +
+				"\tmissing dependency: C/c.test requires X/x.test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateDependencies(normalizePluginsInfo(tt.pluginsInfo))
+			depErr, ok := err.(*DependencyError)
+			if !ok {
+				t.Fatalf("validateDependencies() error = %#v, want *DependencyError", err)
+			}
+			if !reflect.DeepEqual(depErr, tt.want) {
+				t.Errorf("validateDependencies() error = %+v, want %+v", depErr, tt.want)
+			}
+			if depErr.Error() != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", depErr.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm diff is used for comparing the results of two runs to find the
+// regressions in the status and the duration of the plugins.
+package pm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// DiffOptions are the optional parameters to compare the runs.
+type DiffOptions struct {
+	// Threshold is the percentage by which the duration of a plugin should
+	// 	increase to be reported as a duration regression.
+	Threshold float64
+	// MinIncrease is the minimum increase in the duration of a plugin to be
+	// 	reported as a duration regression, so that the plugins running for a
+	// 	short duration are not reported.
+	MinIncrease time.Duration
+}
+
+// StatusChange is the change in the status of a plugin between the runs.
+type StatusChange struct {
+	Plugin    string
+	OldStatus string
+	NewStatus string
+	// Reason is the reason for the status in the new run.
+	Reason string `yaml:",omitempty" json:",omitempty"`
+}
+
+// DependencyChange is the change in the plugins required by a plugin between
+// the runs.
+type DependencyChange struct {
+	Plugin          string
+	AddedRequires   []string `yaml:",omitempty" json:",omitempty"`
+	RemovedRequires []string `yaml:",omitempty" json:",omitempty"`
+}
+
+// DurationChange is the increase in the duration of a plugin between the
+// runs.
+type DurationChange struct {
+	Plugin string
+	// OldSeconds and NewSeconds are the durations of the plugin in seconds.
+	OldSeconds float64
+	NewSeconds float64
+	// Increase is the percentage by which the duration increased.
+	Increase float64
+}
+
+// RunsDiff is the difference between the results of two runs.
+type RunsDiff struct {
+	Old                 string
+	New                 string
+	Added               []string           `yaml:",omitempty" json:",omitempty"`
+	Removed             []string           `yaml:",omitempty" json:",omitempty"`
+	NewlyFailed         []StatusChange     `yaml:",omitempty" json:",omitempty"`
+	Fixed               []StatusChange     `yaml:",omitempty" json:",omitempty"`
+	StatusChanged       []StatusChange     `yaml:",omitempty" json:",omitempty"`
+	DependencyChanges   []DependencyChange `yaml:",omitempty" json:",omitempty"`
+	DurationRegressions []DurationChange   `yaml:",omitempty" json:",omitempty"`
+}
+
+// hasRegressions returns whether the new run has newly failed plugins or
+// duration regressions.
+func (diff RunsDiff) hasRegressions() bool {
+	return len(diff.NewlyFailed) != 0 || len(diff.DurationRegressions) != 0
+}
+
+// getRunPlugins returns the plugins of the run, including the plugins of all
+// its phases.
+func getRunPlugins(result RunStatus) Plugins {
+	pluginsInfo := append(Plugins{}, result.Plugins...)
+	for _, phase := range result.Phases {
+		pluginsInfo = append(pluginsInfo, getRunPlugins(phase)...)
+	}
+	return pluginsInfo
+}
+
+// isFailedStatus returns whether the status is either failed or failed with
+// the failure ignored.
+func isFailedStatus(status string) bool {
+	return status == dStatusFail || status == dStatusFailIgnored
+}
+
+// getPluginDuration returns the duration of the plugin, and whether it ran.
+func getPluginDuration(pInfo Plugin) (time.Duration, bool) {
+	if pInfo.StartTime.IsZero() || pInfo.EndTime.IsZero() {
+		return 0, false
+	}
+	return pInfo.EndTime.Sub(pInfo.StartTime), true
+}
+
+// diffStrings returns the strings present only in the first and only in the
+// second list respectively.
+func diffStrings(list1, list2 []string) ([]string, []string) {
+	only1, only2 := []string{}, []string{}
+	for _, s := range list1 {
+		if !containsString(list2, s) {
+			only1 = append(only1, s)
+		}
+	}
+	for _, s := range list2 {
+		if !containsString(list1, s) {
+			only2 = append(only2, s)
+		}
+	}
+	sort.Strings(only1)
+	sort.Strings(only2)
+	return only1, only2
+}
+
+// diffRuns compares the results of the old and the new runs.
+func diffRuns(oldResult, newResult RunStatus, diffOptions DiffOptions) RunsDiff {
+	diff := RunsDiff{}
+	// INFO: Compare the normalized plugins, so that the dependencies
+	// 	specified using either Requires or RequiredBy are compared alike.
+	oldPlugins := map[string]Plugin{}
+	oldPluginsInfo := getRunPlugins(oldResult)
+	for pIdx, nPInfo := range normalizePluginsInfo(oldPluginsInfo) {
+		oldPlugins[nPInfo.Name] = Plugin{Name: nPInfo.Name, Requires: nPInfo.Requires,
+			Status: oldPluginsInfo[pIdx].Status, Reason: oldPluginsInfo[pIdx].Reason,
+			StartTime: oldPluginsInfo[pIdx].StartTime, EndTime: oldPluginsInfo[pIdx].EndTime}
+	}
+	newPluginsInfo := getRunPlugins(newResult)
+	newNames := []string{}
+	for pIdx, nPInfo := range normalizePluginsInfo(newPluginsInfo) {
+		pInfo := newPluginsInfo[pIdx]
+		newNames = append(newNames, nPInfo.Name)
+		oInfo, ok := oldPlugins[nPInfo.Name]
+		if !ok {
+			diff.Added = append(diff.Added, nPInfo.Name)
+			continue
+		}
+		change := StatusChange{Plugin: nPInfo.Name, OldStatus: oInfo.Status,
+			NewStatus: pInfo.Status, Reason: pInfo.Reason}
+		if isFailedStatus(pInfo.Status) && !isFailedStatus(oInfo.Status) {
+			diff.NewlyFailed = append(diff.NewlyFailed, change)
+		} else if !isFailedStatus(pInfo.Status) && isFailedStatus(oInfo.Status) {
+			diff.Fixed = append(diff.Fixed, change)
+		} else if pInfo.Status != oInfo.Status {
+			diff.StatusChanged = append(diff.StatusChanged, change)
+		}
+
+		removedRequires, addedRequires := diffStrings(oInfo.Requires, nPInfo.Requires)
+		if len(addedRequires) != 0 || len(removedRequires) != 0 {
+			diff.DependencyChanges = append(diff.DependencyChanges, DependencyChange{
+				Plugin: nPInfo.Name, AddedRequires: addedRequires, RemovedRequires: removedRequires})
+		}
+
+		oldDuration, oldRan := getPluginDuration(oInfo)
+		newDuration, newRan := getPluginDuration(pInfo)
+		if !oldRan || !newRan || newDuration-oldDuration < diffOptions.MinIncrease {
+			continue
+		}
+		increase := 100.0
+		if oldDuration > 0 {
+			increase = float64(newDuration-oldDuration) * 100 / float64(oldDuration)
+		}
+		if increase >= diffOptions.Threshold && newDuration > oldDuration {
+			diff.DurationRegressions = append(diff.DurationRegressions, DurationChange{
+				Plugin: nPInfo.Name, OldSeconds: oldDuration.Seconds(),
+				NewSeconds: newDuration.Seconds(), Increase: increase})
+		}
+	}
+	for pName := range oldPlugins {
+		if !containsString(newNames, pName) {
+			diff.Removed = append(diff.Removed, pName)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	for _, changes := range [][]StatusChange{diff.NewlyFailed, diff.Fixed, diff.StatusChanged} {
+		sort.SliceStable(changes, func(i, j int) bool { return changes[i].Plugin < changes[j].Plugin })
+	}
+	sort.SliceStable(diff.DependencyChanges, func(i, j int) bool {
+		return diff.DependencyChanges[i].Plugin < diff.DependencyChanges[j].Plugin
+	})
+	sort.SliceStable(diff.DurationRegressions, func(i, j int) bool {
+		return diff.DurationRegressions[i].Increase > diff.DurationRegressions[j].Increase
+	})
+	return diff
+}
+
+// displayRunsDiff displays the difference between the runs as a report.
+func displayRunsDiff(diff RunsDiff) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Old run:\t%s\nNew run:\t%s\n", diff.Old, diff.New)
+	for _, section := range []struct {
+		title   string
+		plugins []string
+	}{
+		{"Added plugins", diff.Added},
+		{"Removed plugins", diff.Removed},
+	} {
+		if len(section.plugins) != 0 {
+			fmt.Fprintf(w, "\n%s:\n  %s\n", section.title, strings.Join(section.plugins, "\n  "))
+		}
+	}
+	for _, section := range []struct {
+		title   string
+		changes []StatusChange
+	}{
+		{"Newly failed plugins", diff.NewlyFailed},
+		{"Fixed plugins", diff.Fixed},
+		{"Plugins with status changes", diff.StatusChanged},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		for _, change := range section.changes {
+			fmt.Fprintf(w, "  %s\t%s -> %s\t%s\n", change.Plugin, change.OldStatus,
+				change.NewStatus, change.Reason)
+		}
+	}
+	if len(diff.DependencyChanges) != 0 {
+		fmt.Fprintf(w, "\nDependency changes:\n")
+		for _, change := range diff.DependencyChanges {
+			deps := []string{}
+			for _, rs := range change.AddedRequires {
+				deps = append(deps, "+"+rs)
+			}
+			for _, rs := range change.RemovedRequires {
+				deps = append(deps, "-"+rs)
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", change.Plugin, strings.Join(deps, " "))
+		}
+	}
+	if len(diff.DurationRegressions) != 0 {
+		fmt.Fprintf(w, "\nDuration regressions:\n")
+		for _, change := range diff.DurationRegressions {
+			fmt.Fprintf(w, "  %s\t%.3fs -> %.3fs\t+%.0f%%\n", change.Plugin,
+				change.OldSeconds, change.NewSeconds, change.Increase)
+		}
+	}
+	w.Flush()
+	logger.ConsoleInfo.Printf("%s", strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// DiffRuns compares the results of the old and the new runs (i.e., the json
+// or yaml output of the run command), displays the differences, and returns
+// them. An error is returned when the new run has newly failed plugins or
+// duration regressions.
+func DiffRuns(oldFile, newFile string, diffOptions DiffOptions) (RunsDiff, error) {
+	logger.Debug.Printf("Entering DiffRuns(%s, %s, %+v)...", oldFile, newFile, diffOptions)
+	defer logger.Debug.Println("Exiting DiffRuns")
+
+	oldResult, err := getPluginsInfoFromJSONStrOrFile(oldFile)
+	if err != nil {
+		return RunsDiff{}, err
+	}
+	newResult, err := getPluginsInfoFromJSONStrOrFile(newFile)
+	if err != nil {
+		return RunsDiff{}, err
+	}
+	diff := diffRuns(oldResult, newResult, diffOptions)
+	diff.Old, diff.New = oldFile, newFile
+	displayRunsDiff(diff)
+	if diff.hasRegressions() {
+		return diff, fmt.Errorf("The new run has %d newly failed plugins and %d duration regressions.",
+			len(diff.NewlyFailed), len(diff.DurationRegressions))
+	}
+	return diff, nil
+}
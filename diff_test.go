@@ -0,0 +1,180 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_diffRuns(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ran := func(pInfo Plugin, duration time.Duration) Plugin {
+		pInfo.StartTime = start
+		pInfo.EndTime = start.Add(duration)
+		return pInfo
+	}
+	diffOptions := DiffOptions{Threshold: 50, MinIncrease: time.Second}
+	tests := []struct {
+		name      string
+		oldResult RunStatus
+		newResult RunStatus
+		want      RunsDiff
+	}{
+		{
+			name: "No changes",
+			oldResult: RunStatus{Plugins: Plugins{
+				ran(Plugin{Name: "A/a.pre", Status: dStatusOk}, time.Second),
+			}},
+			newResult: RunStatus{Plugins: Plugins{
+				ran(Plugin{Name: "A/a.pre", Status: dStatusOk}, time.Second),
+			}},
+			want: RunsDiff{},
+		},
+		{
+			name: "Added and removed plugins",
+			oldResult: RunStatus{Plugins: Plugins{
+				{Name: "A/a.pre", Status: dStatusOk},
+				{Name: "B/b.pre", Status: dStatusOk},
+			}},
+			newResult: RunStatus{Plugins: Plugins{
+				{Name: "A/a.pre", Status: dStatusOk},
+				{Name: "C/c.pre", Status: dStatusOk},
+			}},
+			want: RunsDiff{Added: []string{"C/c.pre"}, Removed: []string{"B/b.pre"}},
+		},
+		{
+			name: "Status changes",
+			oldResult: RunStatus{Plugins: Plugins{
+				{Name: "A/a.pre", Status: dStatusOk},
+				{Name: "B/b.pre", Status: dStatusFail},
+				{Name: "C/c.pre", Status: dStatusOk},
+				{Name: "D/d.pre", Status: dStatusOk},
+			}},
+			newResult: RunStatus{Plugins: Plugins{
+				{Name: "A/a.pre", Status: dStatusFailIgnored, Reason: "exit status 1"},
+				{Name: "B/b.pre", Status: dStatusOk},
+				{Name: "C/c.pre", Status: dStatusSkip},
+				{Name: "D/d.pre", Status: dStatusOk},
+			}},
+			want: RunsDiff{
+				NewlyFailed: []StatusChange{{Plugin: "A/a.pre", OldStatus: dStatusOk,
+					NewStatus: dStatusFailIgnored, Reason: "exit status 1"}},
+				Fixed: []StatusChange{{Plugin: "B/b.pre", OldStatus: dStatusFail,
+					NewStatus: dStatusOk}},
+				StatusChanged: []StatusChange{{Plugin: "C/c.pre", OldStatus: dStatusOk,
+					NewStatus: dStatusSkip}},
+			},
+		},
+		{
+			name: "Dependency changes using Requires and RequiredBy",
+			oldResult: RunStatus{Plugins: Plugins{
+				{Name: "A/a.pre", Status: dStatusOk, RequiredBy: []string{"C/c.pre"}},
+				{Name: "B/b.pre", Status: dStatusOk},
+				{Name: "C/c.pre", Status: dStatusOk},
+			}},
+			newResult: RunStatus{Plugins: Plugins{
+				{Name: "A/a.pre", Status: dStatusOk},
+				{Name: "B/b.pre", Status: dStatusOk},
+				{Name: "C/c.pre", Status: dStatusOk, Requires: []string{"B/b.pre"}},
+			}},
+			want: RunsDiff{DependencyChanges: []DependencyChange{{Plugin: "C/c.pre",
+				AddedRequires: []string{"B/b.pre"}, RemovedRequires: []string{"A/a.pre"}}}},
+		},
+		{
+			name: "Duration regressions",
+			oldResult: RunStatus{Phases: []RunStatus{
+				{Plugins: Plugins{
+					ran(Plugin{Name: "A/a.pre", Status: dStatusOk}, 10*time.Second),
+					ran(Plugin{Name: "B/b.pre", Status: dStatusOk}, 10*time.Second),
+					ran(Plugin{Name: "C/c.pre", Status: dStatusOk}, 100*time.Millisecond),
+				}},
+				{Plugins: Plugins{
+					ran(Plugin{Name: "D/d.post", Status: dStatusOk}, 2*time.Second),
+					{Name: "E/e.post", Status: dStatusSkip},
+				}},
+			}},
+			newResult: RunStatus{Phases: []RunStatus{
+				{Plugins: Plugins{
+					ran(Plugin{Name: "A/a.pre", Status: dStatusOk}, 20*time.Second),
+					ran(Plugin{Name: "B/b.pre", Status: dStatusOk}, 12*time.Second),
+					ran(Plugin{Name: "C/c.pre", Status: dStatusOk}, 500*time.Millisecond),
+				}},
+				{Plugins: Plugins{
+					ran(Plugin{Name: "D/d.post", Status: dStatusOk}, 8*time.Second),
+					ran(Plugin{Name: "E/e.post", Status: dStatusOk}, 8*time.Second),
+				}},
+			}},
+			want: RunsDiff{
+				StatusChanged: []StatusChange{{Plugin: "E/e.post", OldStatus: dStatusSkip,
+					NewStatus: dStatusOk}},
+				DurationRegressions: []DurationChange{
+					{Plugin: "D/d.post", OldSeconds: 2, NewSeconds: 8, Increase: 300},
+					{Plugin: "A/a.pre", OldSeconds: 10, NewSeconds: 20, Increase: 100},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffRuns(tt.oldResult, tt.newResult, diffOptions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffRuns() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffRuns(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	dir := t.TempDir()
+	writeResult := func(name string, result RunStatus) string {
+		file := filepath.Join(dir, name)
+		bytes, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		if err = os.WriteFile(file, bytes, 0644); err != nil {
+			t.Fatalf("os.WriteFile() error = %v", err)
+		}
+		return file
+	}
+	okFile := writeResult("ok.json", RunStatus{Type: "pre", Status: dStatusOk,
+		Plugins: Plugins{{Name: "A/a.pre", Status: dStatusOk}}})
+	failFile := writeResult("fail.json", RunStatus{Type: "pre", Status: dStatusFail,
+		Plugins: Plugins{{Name: "A/a.pre", Status: dStatusFail}}})
+
+	tests := []struct {
+		name    string
+		oldFile string
+		newFile string
+		wantErr bool
+	}{
+		{name: "Fixed plugins", oldFile: failFile, newFile: okFile},
+		{name: "Newly failed plugins", oldFile: okFile, newFile: failFile, wantErr: true},
+		{name: "Missing run result", oldFile: okFile, newFile: filepath.Join(dir, "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffRuns(tt.oldFile, tt.newFile, DiffOptions{Threshold: 50, MinIncrease: time.Second})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DiffRuns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (diff.Old != tt.oldFile || diff.New != tt.newFile) {
+				t.Errorf("DiffRuns() = %+v, want the runs %s and %s", diff, tt.oldFile, tt.newFile)
+			}
+		})
+	}
+}
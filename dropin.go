@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm dropin is used for applying the drop-in override files over the
+// plugin files.
+package pm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// pluginDropIn is a drop-in file overriding the plugin file.
+type pluginDropIn struct {
+	// file is the drop-in file name displayed to the user. It's relative to
+	// 	the plugins library for drop-ins present in the library, and is the
+	// 	complete path for drop-ins present in the override library.
+	file string
+	// path is the complete path of the drop-in file.
+	path string
+}
+
+// getPluginDropIns returns the drop-in files (i.e., "<plugin>.d/*.conf") of
+// the specified plugin from the plugins library and the override library,
+// sorted by their file names.
+//
+//	NOTE: Similar to systemd, a drop-in file in the override library replaces
+//	the drop-in file with the same name in the plugins library.
+func getPluginDropIns(library, overrideLibrary, pName string) []pluginDropIn {
+	dropIns := map[string]pluginDropIn{}
+	for _, lib := range []string{library, overrideLibrary} {
+		if lib == "" {
+			continue
+		}
+		dropInDir := filepath.FromSlash(lib + "/" + pName + ".d")
+		files, err := ioutil.ReadDir(dropInDir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Error.Printf("Unable to read contents of %s directory, err=%s", dropInDir, err.Error())
+			}
+			continue
+		}
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), ".conf") {
+				continue
+			}
+			dropIn := pluginDropIn{
+				file: filepath.FromSlash(pName + ".d/" + f.Name()),
+				path: filepath.Join(dropInDir, f.Name()),
+			}
+			if lib == overrideLibrary {
+				dropIn.file = dropIn.path
+			}
+			dropIns[f.Name()] = dropIn
+		}
+	}
+
+	names := []string{}
+	for name := range dropIns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sortedDropIns := []pluginDropIn{}
+	for _, name := range names {
+		sortedDropIns = append(sortedDropIns, dropIns[name])
+	}
+	return sortedDropIns
+}
+
+// loadPlugin parses the plugin file, and applies its drop-in files over it.
+// It returns the plugin info along with the files that were parsed.
+func loadPlugin(library, overrideLibrary, file string, up *unitParser) (Plugin, []string, error) {
+	files := []string{file}
+	fContents, err := readFile(filepath.FromSlash(library + file))
+	if err != nil {
+		return Plugin{}, files, logger.ConsoleError.PrintNReturnError(err.Error())
+	}
+	logger.Debug.Printf("Plugin file %s contents: \n%s\n", file, fContents)
+	up.file = file
+	pInfo, err := parsePluginFile(file, fContents, up)
+	if err != nil {
+		return pInfo, files, err
+	}
+
+	pName := getPluginName(file)
+	for _, dropIn := range getPluginDropIns(library, overrideLibrary, pName) {
+		fContents, err = readFile(dropIn.path)
+		if err != nil {
+			return pInfo, files, logger.ConsoleError.PrintNReturnError(err.Error())
+		}
+		logger.Debug.Printf("Plugin drop-in file %s contents: \n%s\n", dropIn.file, fContents)
+		up.file = dropIn.file
+		pInfo, err = up.parseOnto(pInfo, fContents)
+		if err != nil {
+			return pInfo, files, err
+		}
+		files = append(files, dropIn.file)
+	}
+	pInfo.Name = pName
+	return pInfo, files, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func Test_getPluginsInfoFromLibrary_dropIns(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.test":                  "Description=A\nExecStart=/bin/echo A\nRequires=B/b.test\n",
+		"A/a.test.d/10-deps.conf":   "[Unit]\nRequires=C/c.test\n",
+		"A/a.test.d/20-desc.conf":   "Description=A from library drop-in\n",
+		"A/a.test.d/README":         "Not a drop-in file.\n",
+		"B/b.test.yaml":             "description: B\nexecstart: /bin/echo B\n",
+		"B/b.test.d/10-reset.conf":  "ExecStart=\nRequiredBy=\nRequiredBy=C/c.test\n",
+		"C/c.test":                  "Description=C\nTimeoutSec=10\n",
+		"C/c.test.d/10-ignore.conf": "FailureMode=ignore\n",
+	})
+	overrideLibrary := createTestLibrary(t, map[string]string{
+		"A/a.test.d/20-desc.conf":    "Description=A from override drop-in\n",
+		"B/b.test.d/20-disable.conf": "[Install]\nDisabled=yes\n",
+		"C/c.test.d/30-fatal.conf":   "FailureMode=fatal\nTimeoutSec=60\n",
+	})
+	defer config.SetPluginsOverrideLibrary(config.GetPluginsOverrideLibrary())
+	config.SetPluginsOverrideLibrary(overrideLibrary)
+
+	want := Plugins{
+		{Name: "A/a.test", Description: "A from override drop-in", ExecStart: "/bin/echo A",
+			Requires: []string{"B/b.test", "C/c.test"}},
+		{Name: "B/b.test", Description: "B", RequiredBy: []string{"C/c.test"}, Disabled: true},
+		{Name: "C/c.test", Description: "C", FailureMode: failureModeFatal, TimeoutSec: 60},
+	}
+	got, err := getPluginsInfoFromLibrary("test", library)
+	if err != nil {
+		t.Fatalf("getPluginsInfoFromLibrary() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getPluginsInfoFromLibrary() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm generator is used for generating the plugins at run time using
+// the generators (i.e., "<component>/<name>.<type>-gen") in the library.
+package pm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	osutils "github.com/VeritasOS/plugin-manager/utils/os"
+)
+
+// generatorSuffix is appended to the plugin type to name the generators of
+// the plugins of that type (Ex: "X/generator.preupgrade-gen").
+const generatorSuffix = "-gen"
+
+// generatorTimeout is the maximum time a generator could run, so that a hung
+// generator doesn't block reading the plugins.
+var generatorTimeout = 60 * time.Second
+
+// getGeneratorFiles returns the generators of the plugins of specified type
+// under each component of the library.
+func getGeneratorFiles(pluginType, library string) ([]string, error) {
+	var generatorFiles []string
+	files, err := getLibraryFiles(library, config.GetLibraryDepth())
+	if err != nil {
+		return generatorFiles, err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "."+pluginType+generatorSuffix) {
+			generatorFiles = append(generatorFiles, file)
+		}
+	}
+	return generatorFiles, nil
+}
+
+// runGenerator runs the generator, and returns the plugins it printed as a
+// JSON list of plugins in the Plugin schema (i.e., same as that of the plugin
+// definition files along with the Name).
+//
+//	NOTE: The plugin name without a component (Ex: "a.preupgrade") is
+//	considered to be of the generator's component. The generator (along with
+//	the processes started by it) is terminated when it doesn't complete within
+//	generatorTimeout.
+func runGenerator(pluginType, library, file string) (Plugins, error) {
+	var pluginsInfo Plugins
+	ctx, cancel := context.WithTimeout(context.Background(), generatorTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, filepath.Join(library, file))
+	setPluginProcessGroup(cmd)
+	cmd.WaitDelay = pluginWaitDelay
+	cmd.Env = append(osutils.OsEnviron(), "PM_LIBRARY="+library)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	logger.Info.Printf("Generator %s output: %s", file, stdout.String())
+	if stderr.Len() != 0 {
+		logger.Info.Printf("Generator %s error output: %s", file, stderr.String())
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return pluginsInfo, fmt.Errorf("Timed out after %s.", generatorTimeout)
+	} else if err != nil && stderr.Len() != 0 {
+		return pluginsInfo, fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(stderr.String()))
+	} else if err != nil {
+		return pluginsInfo, err
+	}
+
+	var generated Plugins
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&generated); err != nil {
+		return pluginsInfo, fmt.Errorf("Output is not in expected format. Error: %s", err.Error())
+	}
+	component := path.Dir(filepath.ToSlash(file))
+	for _, gInfo := range generated {
+		pName := gInfo.Name
+		if pName != "" && !strings.Contains(pName, "/") {
+			pName = component + "/" + pName
+		}
+		if getPluginType(pName) != pluginType {
+			return pluginsInfo, fmt.Errorf(
+				"Invalid plugin name '%s'. Expected format is '<component>/<plugin-file>.%s'.",
+				gInfo.Name, pluginType)
+		}
+		// INFO: Only the plugin definition fields are considered, and the run
+		// 	time fields like Status are ignored.
+		pluginsInfo = append(pluginsInfo, Plugin{
+			Name:                 pName,
+			Description:          gInfo.Description,
+			ExecStart:            gInfo.ExecStart,
+			RequiredBy:           gInfo.RequiredBy,
+			Requires:             gInfo.Requires,
+			FailureMode:          gInfo.FailureMode,
+			SkipOnIgnoredFailure: gInfo.SkipOnIgnoredFailure,
+			TimeoutSec:           gInfo.TimeoutSec,
+			Disabled:             gInfo.Disabled,
+			Tags:                 gInfo.Tags,
+			Instances:            gInfo.Instances,
+			InstancesGlob:        gInfo.InstancesGlob,
+			InstancesFrom:        gInfo.InstancesFrom,
+			Generator:            file,
+		})
+	}
+	return pluginsInfo, nil
+}
+
+// runGenerators runs the generators of the plugins of specified type, and
+// returns the generated plugins.
+//
+//	NOTE: A failing generator doesn't fail reading the plugins, and is
+//	displayed as a warning, as the plugins of the other components could still
+//	be listed or run.
+func runGenerators(pluginType, library string) (Plugins, error) {
+	var pluginsInfo Plugins
+	generatorFiles, err := getGeneratorFiles(pluginType, library)
+	if err != nil {
+		return pluginsInfo, err
+	}
+	for _, file := range generatorFiles {
+		generated, gerr := runGenerator(pluginType, library, file)
+		if gerr != nil {
+			logger.ConsoleWarning.Printf("Generator %s failed, and none of its plugins are considered. Error: %s",
+				file, gerr.Error())
+			continue
+		}
+		logger.Info.Printf("Generator %s generated %d plugins.", file, len(generated))
+		pluginsInfo = append(pluginsInfo, generated...)
+	}
+	return pluginsInfo, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func Test_getPluginsInfoFromLibrary_generators(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    Plugins
+		wantErr bool
+	}{
+		{
+			name: "Generated plugins along with the plugin files",
+			files: map[string]string{
+				"A/a.test": "Description=A\nExecStart=/bin/echo A\n",
+				"X/gen.test-gen": "#!/bin/sh\necho '[" +
+					`{"Name": "x1.test", "Description": "X1", "ExecStart": "/bin/echo X1", "Requires": ["A/a.test"]},` +
+					`{"Name": "Y/y@.test", "Description": "Y %i", "Instances": ["sda"], "Status": "Failed"}` +
+					"]'\n",
+				"X/gen.check-gen": "#!/bin/sh\necho '[{\"Name\": \"x.check\"}]'\n",
+			},
+			want: Plugins{
+				{Name: "A/a.test", Description: "A", ExecStart: "/bin/echo A"},
+				{Name: "X/x1.test", Description: "X1", ExecStart: "/bin/echo X1",
+					Requires: []string{"A/a.test"}, Generator: "X/gen.test-gen"},
+				{Name: "Y/y@sda.test", Description: "Y sda",
+					RequiredBy: []string{}, Requires: []string{}, Generator: "X/gen.test-gen"},
+			},
+		},
+		{
+			name: "Failing generators are skipped",
+			files: map[string]string{
+				"A/a.test":        "Description=A\n",
+				"X/fail.test-gen": "#!/bin/sh\necho 'no disks' >&2\nexit 1\n",
+				"Y/bad.test-gen":  "#!/bin/sh\necho 'Name=y.test'\n",
+				"Z/type.test-gen": "#!/bin/sh\necho '[{\"Name\": \"z.check\"}]'\n",
+				"W/key.test-gen":  "#!/bin/sh\necho '[{\"Name\": \"w.test\", \"Foo\": \"bar\"}]'\n",
+				"V/hang.test-gen": "#!/bin/sh\nsleep 30\necho '[{\"Name\": \"v.test\"}]'\n",
+			},
+			want: Plugins{
+				{Name: "A/a.test", Description: "A"},
+			},
+		},
+		{
+			name: "Generated plugin conflicting with a plugin file",
+			files: map[string]string{
+				"A/a.test":       "Description=A\n",
+				"A/gen.test-gen": "#!/bin/sh\necho '[{\"Name\": \"a.test\"}]'\n",
+			},
+			wantErr: true,
+		},
+	}
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+	defer func(timeout time.Duration) { generatorTimeout = timeout }(generatorTimeout)
+	generatorTimeout = time.Second
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := createTestLibrary(t, tt.files)
+			got, err := getPluginsInfoFromLibrary("test", library)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPluginsInfoFromLibrary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPluginsInfoFromLibrary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/VeritasOS/plugin-manager
 
-go 1.19
+go 1.20
 
 require gopkg.in/yaml.v3 v3.0.1
@@ -27,11 +27,18 @@ type graph struct {
 	//  I.e., each subgraph name is the key, and their contents would be in
 	// 	an array.
 	subgraph sync.Map
+	// crossTypeEdges are the dependencies between the plugins of different
+	// 	types (i.e., subgraphs), which are drawn outside the subgraphs so
+	// 	that the plugins stay in their own subgraphs.
+	crossTypeEdges sync.Map
 }
 
 var g graph
 var dotCmdPresent = true
 
+// graphRenderer is the renderer used for generating the graph image.
+var graphRenderer = graphRendererDot
+
 func initGraphConfig(imgNamePrefix string) {
 	// Initialization should be done only once.
 	if g.fileNoExt == "" {
@@ -73,38 +80,53 @@ func initGraph(pluginType string, pluginsInfo Plugins) error {
 		absLogPath, _ := filepath.Abs(config.GetPMLogDir())
 		absLibraryPath, _ := filepath.Abs(config.GetPluginsLibrary())
 		relPath, _ := filepath.Rel(absLogPath, absLibraryPath)
-		pURL := "\"" + filepath.FromSlash(relPath+string(os.PathSeparator)+pName) + "\""
+		// INFO: The generated plugins are linked to their generators.
+		pFile := pName
+		if pluginsInfo[pIdx].Generator != "" {
+			pFile = pluginsInfo[pIdx].Generator
+		}
+		pURL := "\"" + filepath.FromSlash(relPath+string(os.PathSeparator)+pFile) + "\""
 		rows := []string{}
 		rowsInterface, ok := g.subgraph.Load(pluginType)
 		if ok {
 			rows = rowsInterface.([]string)
 		}
-		rows = append(rows, pFileString+" [label=\""+
-			strings.Replace(pluginsInfo[pIdx].Description, "\"", `\"`, -1)+
-			"\",style=filled,fillcolor=lightgrey,URL="+pURL+"]")
+		label := strings.Replace(pluginsInfo[pIdx].Description, "\"", `\"`, -1)
+		if len(pluginsInfo[pIdx].Tags) != 0 {
+			label += `\n[` + strings.Join(pluginsInfo[pIdx].Tags, ", ") + "]"
+		}
+		if pluginsInfo[pIdx].Generator != "" {
+			label += `\n(generated by ` + pluginsInfo[pIdx].Generator + ")"
+		}
+		rows = append(rows, pFileString+" [label=\""+label+"\",style=filled,fillcolor=lightgrey,URL="+pURL+"]")
 		rows = append(rows, "\""+pName+"\"")
-		rbyLen := len(pluginsInfo[pIdx].RequiredBy)
-		if rbyLen != 0 {
-			graphRow := "\"" + pName + "\" -> "
-			for rby := range pluginsInfo[pIdx].RequiredBy {
-				graphRow += "\"" + pluginsInfo[pIdx].RequiredBy[rby] + "\""
-				if rby != rbyLen-1 {
-					graphRow += ", "
-				}
+		requiredBy := []string{}
+		for _, rby := range pluginsInfo[pIdx].RequiredBy {
+			if getPluginType(rby) != pluginType {
+				g.crossTypeEdges.Store("\""+pName+"\" -> \""+rby+"\"", true)
+				continue
 			}
-			rows = append(rows, graphRow)
+			requiredBy = append(requiredBy, rby)
+		}
+		if len(requiredBy) != 0 {
+			rows = append(rows, "\""+pName+"\" -> \""+strings.Join(requiredBy, "\", \"")+"\"")
 		}
-		rsLen := len(pluginsInfo[pIdx].Requires)
-		if rsLen != 0 {
-			graphRow := ""
-			for rs := range pluginsInfo[pIdx].Requires {
-				graphRow += "\"" + pluginsInfo[pIdx].Requires[rs] + "\""
-				if rs != rsLen-1 {
-					graphRow += ", "
-				}
+		requires := []string{}
+		for _, rs := range pluginsInfo[pIdx].Requires {
+			if isPluginRef(rs) {
+				// NOTE: Plugin references are drawn as dashed edges from the
+				// 	referenced plugin.
+				g.crossTypeEdges.Store("\""+getPluginRefName(rs)+"\" -> \""+pName+"\" [style=dashed]", true)
+				continue
 			}
-			graphRow += " -> \"" + pName + "\""
-			rows = append(rows, graphRow)
+			if getPluginType(rs) != pluginType {
+				g.crossTypeEdges.Store("\""+rs+"\" -> \""+pName+"\"", true)
+				continue
+			}
+			requires = append(requires, rs)
+		}
+		if len(requires) != 0 {
+			rows = append(rows, "\""+strings.Join(requires, "\", \"")+"\" -> \""+pName+"\"")
 		}
 		g.subgraph.Store(pluginType, rows)
 	}
@@ -126,7 +148,10 @@ func generateGraph() error {
 	}
 	defer fhDigraph.Close()
 	clusterCnt := 0
-	graphContent := "digraph {\n"
+	// INFO: Use strict graph so that an edge specified more than once is drawn
+	// 	only once, and the attributes specified later (like highlighting of
+	// 	dependency issues) get applied to the same edge.
+	graphContent := "strict digraph {\n"
 	g.subgraph.Range(func(name interface{}, rows interface{}) bool {
 		graphContent += "\nsubgraph cluster_" + strconv.Itoa(clusterCnt) + " {\n" +
 			"label=\"" + name.(string) + " plugins\"\nlabelloc=t\nfontsize=24\n" +
@@ -135,6 +160,15 @@ func generateGraph() error {
 		clusterCnt++
 		return true
 	})
+	crossTypeEdges := []string{}
+	g.crossTypeEdges.Range(func(edge interface{}, _ interface{}) bool {
+		crossTypeEdges = append(crossTypeEdges, edge.(string))
+		return true
+	})
+	sort.Strings(crossTypeEdges)
+	if len(crossTypeEdges) != 0 {
+		graphContent += "\n" + strings.Join(crossTypeEdges, "\n") + "\n"
+	}
 	graphContent += "\n}\n"
 
 	_, writeerr := fhDigraph.WriteString(graphContent)
@@ -145,9 +179,9 @@ func generateGraph() error {
 
 	// https://graphviz.gitlab.io/_pages/doc/info/command.html
 	cmdStr := "dot"
-	// If cmdStr is not installed on system, then just return.
-	if !dotCmdPresent {
-		return nil
+	// If cmdStr is not installed on system, then use the builtin renderer.
+	if graphRenderer == graphRendererBuiltin || !dotCmdPresent {
+		return renderGraph(svgFile)
 	}
 	cmdParams := []string{"-Tsvg", dotFile, "-o", svgFile}
 
@@ -155,8 +189,10 @@ func generateGraph() error {
 	stdOutErr, err := cmd.CombinedOutput()
 	if err != nil {
 		if strings.Contains(err.Error(), "executable file not found in $PATH") {
+			logger.Info.Printf("%s command is not present, so using the %s graph renderer.",
+				cmdStr, graphRendererBuiltin)
 			dotCmdPresent = false
-			return nil
+			return renderGraph(svgFile)
 		}
 		logger.Error.Printf("osutils.ExecCommand(%v, %v), err=%s", cmd, cmdParams, err.Error())
 	}
@@ -173,6 +209,8 @@ func getStatusColor(status string) string {
 	ncolor := "blue" // dStatusStart by default
 	if status == dStatusFail {
 		ncolor = "red"
+	} else if status == dStatusFailIgnored {
+		ncolor = "orange"
 	} else if status == dStatusOk {
 		ncolor = "green"
 	} else if status == dStatusSkip {
@@ -194,3 +232,67 @@ func updateGraph(subgraphName, plugin, status, url string) error {
 
 	return generateGraph()
 }
+
+// highlightDependencyIssues highlights the plugins and dependencies forming a
+// cycle, as well as the missing plugins in the graph.
+func highlightDependencyIssues(subgraphName string, depErr *DependencyError) error {
+	gContents := []string{}
+	gContentsInterface, ok := g.subgraph.Load(subgraphName)
+	if ok {
+		gContents = gContentsInterface.([]string)
+	}
+	for _, cycle := range depErr.Cycles {
+		for i := 0; i < len(cycle)-1; i++ {
+			// NOTE: Edges are drawn from the required plugin to the plugin
+			// 	that requires it.
+			gContents = append(gContents,
+				"\""+cycle[i]+"\" [color=red,penwidth=3]",
+				"\""+cycle[i+1]+"\" -> \""+cycle[i]+"\" [color=red,penwidth=3]")
+		}
+	}
+	for _, m := range depErr.Missing {
+		gContents = append(gContents,
+			"\""+m.Requires+"\" [label=\""+m.Requires+" (missing)\",style=dashed,color=red]")
+	}
+	g.subgraph.Store(subgraphName, gContents)
+
+	return generateGraph()
+}
+
+// highlightCriticalPath colors the plugins as per their status, and
+// highlights the plugins and the dependencies on the critical path in the
+// graph.
+func highlightCriticalPath(pluginsInfo Plugins, criticalPath []string, criticalEdges [][2]string) error {
+	for _, pInfo := range pluginsInfo {
+		subgraphName := getPluginType(pInfo.Name)
+		gContents := []string{}
+		gContentsInterface, ok := g.subgraph.Load(subgraphName)
+		if ok {
+			gContents = gContentsInterface.([]string)
+		}
+		attrs := "style=filled,fillcolor=" + getStatusColor(pInfo.Status)
+		if containsString(criticalPath, pInfo.Name) {
+			attrs += ",color=purple,penwidth=3"
+		}
+		gContents = append(gContents, "\""+pInfo.Name+"\" ["+attrs+"]")
+		g.subgraph.Store(subgraphName, gContents)
+	}
+	for _, edge := range criticalEdges {
+		// NOTE: Edges are drawn from the required plugin to the plugin that
+		// 	requires it.
+		edgeRow := "\"" + edge[0] + "\" -> \"" + edge[1] + "\" [color=purple,penwidth=3]"
+		subgraphName := getPluginType(edge[1])
+		if getPluginType(edge[0]) != subgraphName {
+			g.crossTypeEdges.Store(edgeRow, true)
+			continue
+		}
+		gContents := []string{}
+		gContentsInterface, ok := g.subgraph.Load(subgraphName)
+		if ok {
+			gContents = gContentsInterface.([]string)
+		}
+		g.subgraph.Store(subgraphName, append(gContents, edgeRow))
+	}
+
+	return generateGraph()
+}
@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
 )
 
 func Test_getStatusColor(t *testing.T) {
@@ -42,6 +44,11 @@ func Test_getStatusColor(t *testing.T) {
 			args: args{status: dStatusSkip},
 			want: "yellow",
 		},
+		{
+			name: "Fail ignored",
+			args: args{status: dStatusFailIgnored},
+			want: "orange",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -196,8 +203,57 @@ func Test_initGraph(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Plugin with tags",
+			args: args{
+				pluginType: "test4",
+				pluginsInfo: Plugins{
+					{
+						Name:        "A/a.test4",
+						Description: "A's description",
+						Tags:        []string{"storage", "quick"},
+					},
+				},
+			},
+			wantrows: []string{
+				`"A/a.test4" [label="A's description\n[storage, quick]",style=filled,fillcolor=lightgrey,URL="./A/a.test4"]`,
+				`"A/a.test4"`,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Cross-type dependencies are drawn outside the subgraph",
+			args: args{
+				pluginType: "test5",
+				pluginsInfo: Plugins{
+					{
+						Name:        "A/a.test5",
+						Description: "A's description",
+						Requires:    []string{"B/b.test5", "X/x.pre"},
+						RequiredBy:  []string{"Y/y.post"},
+					},
+					{
+						Name:        "B/b.test5",
+						Description: "B's description",
+					},
+				},
+			},
+			wantrows: []string{
+				`"A/a.test5" [label="A's description",style=filled,fillcolor=lightgrey,URL="./A/a.test5"]`,
+				`"A/a.test5"`,
+				`"B/b.test5" [label="B's description",style=filled,fillcolor=lightgrey,URL="./B/b.test5"]`,
+				`"B/b.test5"`,
+				`"B/b.test5" -> "A/a.test5"`,
+			},
+			wantErr: false,
+		},
 	}
 
+	// INFO: The plugin URLs are relative to the log dir, so keep the library
+	// 	in the log dir for the URLs to be independent of the test dirs.
+	library := config.GetPluginsLibrary()
+	config.SetPluginsLibrary(config.GetPMLogDir())
+	defer config.SetPluginsLibrary(library)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if err := initGraph(tt.args.pluginType, tt.args.pluginsInfo); (err != nil) != tt.wantErr {
@@ -212,6 +268,13 @@ func Test_initGraph(t *testing.T) {
 			}
 			sort.Strings(rowsI.([]string))
 			sort.Strings(tt.wantrows)
+			if tt.args.pluginType == "test5" {
+				for _, edge := range []string{`"X/x.pre" -> "A/a.test5"`, `"A/a.test5" -> "Y/y.post"`} {
+					if _, ok := g.crossTypeEdges.Load(edge); !ok {
+						t.Errorf("initGraph() cross-type edge %s is missing", edge)
+					}
+				}
+			}
 			if !reflect.DeepEqual(tt.wantrows, rowsI.([]string)) {
 				t.Errorf("initGraph() got = %+v (%d), want %+v (%d)",
 					rowsI, len(rowsI.([]string)), tt.wantrows, len(tt.wantrows))
@@ -219,3 +282,29 @@ func Test_initGraph(t *testing.T) {
 		})
 	}
 }
+
+func Test_highlightDependencyIssues(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	depErr := &DependencyError{
+		Cycles:  [][]string{{"A/a.cycle", "B/b.cycle", "A/a.cycle"}},
+		Missing: []MissingDependency{{Plugin: "C/c.cycle", Requires: "X/x.cycle"}},
+	}
+	want := []string{
+		`"A/a.cycle" [color=red,penwidth=3]`,
+		`"B/b.cycle" -> "A/a.cycle" [color=red,penwidth=3]`,
+		`"B/b.cycle" [color=red,penwidth=3]`,
+		`"A/a.cycle" -> "B/b.cycle" [color=red,penwidth=3]`,
+		`"X/x.cycle" [label="X/x.cycle (missing)",style=dashed,color=red]`,
+	}
+	if err := highlightDependencyIssues("cycle", depErr); err != nil {
+		t.Errorf("highlightDependencyIssues() error = %v", err)
+	}
+	rowsI, _ := g.subgraph.Load("cycle")
+	if !reflect.DeepEqual(rowsI.([]string), want) {
+		t.Errorf("highlightDependencyIssues() rows = %v, want %v", rowsI, want)
+	}
+}
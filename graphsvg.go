@@ -0,0 +1,568 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm graphsvg is used for rendering the graph as an SVG image without
+// the graphviz dot command, using a layered layout of the plugins.
+package pm
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Graph renderers i.e., how the graph image is generated from the graph.
+const (
+	// graphRendererDot renders the graph using the graphviz dot command, and
+	// 	falls back to graphRendererBuiltin when dot is not present.
+	graphRendererDot = "dot"
+	// graphRendererBuiltin renders the graph without any external command.
+	graphRendererBuiltin = "builtin"
+)
+
+// Dimensions of the graph image rendered by the builtin renderer in pixels.
+const (
+	svgMargin         = 20
+	svgNodeGap        = 20
+	svgLayerGap       = 40
+	svgClusterPadding = 15
+	svgClusterLabel   = 30
+	svgClusterGap     = 30
+	svgCharWidth      = 7
+	svgLineHeight     = 16
+)
+
+// svgNode is a node of the graph i.e., a plugin.
+type svgNode struct {
+	name string
+	// cluster is the subgraph (i.e., plugin type) in which the node was
+	// 	first specified, or an empty string when it's outside subgraphs.
+	cluster string
+	// attrs are the DOT attributes of the node, where the attributes
+	// 	specified later override the earlier ones.
+	attrs               map[string]string
+	layer               int
+	x, y, width, height float64
+	requires            []string
+}
+
+// svgEdge is an edge of the graph from the required plugin to the plugin that
+// requires it.
+type svgEdge struct {
+	from, to string
+	attrs    map[string]string
+}
+
+// svgCluster is a subgraph of the graph i.e., the plugins of a type.
+type svgCluster struct {
+	name                string
+	nodes               []*svgNode
+	x, y, width, height float64
+}
+
+// svgGraph is the graph parsed from the DOT statements of the subgraphs and the
+// cross type edges.
+type svgGraph struct {
+	nodes    map[string]*svgNode
+	edges    []*svgEdge
+	edgeIdx  map[[2]string]*svgEdge
+	clusters map[string]*svgCluster
+}
+
+// readDotQuoted returns the contents of the quoted string at the start of the
+// DOT text (with its escape sequences as is), and the text after it.
+func readDotQuoted(text string) (string, string) {
+	for idx := 1; idx < len(text); idx++ {
+		if text[idx] == '\\' {
+			idx++
+			continue
+		}
+		if text[idx] == '"' {
+			return text[1:idx], text[idx+1:]
+		}
+	}
+	return text[1:], ""
+}
+
+// parseDotAttrs adds the attributes of the DOT attribute list (i.e., the
+// contents of "[...]") to attrs.
+func parseDotAttrs(list string, attrs map[string]string) {
+	for list = strings.TrimSpace(list); list != ""; list = strings.TrimSpace(list) {
+		key, rest, found := strings.Cut(list, "=")
+		if !found {
+			return
+		}
+		rest = strings.TrimSpace(rest)
+		val := ""
+		if strings.HasPrefix(rest, "\"") {
+			val, rest = readDotQuoted(rest)
+		} else {
+			val, rest, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		list = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+}
+
+// parseDotStatement parses the DOT node or edge statement generated for the
+// graph (Ex: `"A/a.pre", "B/b.pre" -> "C/c.pre" [color=red]`), and returns
+// the groups of the node IDs separated by "->", along with the attributes.
+func parseDotStatement(stmt string) ([][]string, map[string]string) {
+	groups := [][]string{{}}
+	attrs := map[string]string{}
+	for text := strings.TrimSpace(stmt); text != ""; {
+		switch {
+		case text[0] == '"':
+			var id string
+			id, text = readDotQuoted(text)
+			groups[len(groups)-1] = append(groups[len(groups)-1], id)
+		case strings.HasPrefix(text, "->"):
+			groups = append(groups, []string{})
+			text = text[2:]
+		case text[0] == '[':
+			end := len(text)
+			for idx, inQuotes := 1, false; idx < len(text); idx++ {
+				if text[idx] == '\\' {
+					idx++
+				} else if text[idx] == '"' {
+					inQuotes = !inQuotes
+				} else if text[idx] == ']' && !inQuotes {
+					end = idx
+					break
+				}
+			}
+			parseDotAttrs(text[1:end], attrs)
+			if end < len(text) {
+				end++
+			}
+			text = text[end:]
+		default:
+			text = text[1:]
+		}
+	}
+	return groups, attrs
+}
+
+// addNode adds the node to the cluster, unless it's already present.
+func (sg *svgGraph) addNode(name, cluster string) *svgNode {
+	if node, ok := sg.nodes[name]; ok {
+		return node
+	}
+	node := &svgNode{name: name, cluster: cluster, attrs: map[string]string{}}
+	if cluster != "" {
+		// NOTE: Same as the node defaults of the subgraphs in the DOT file.
+		node.attrs["style"] = "filled"
+		node.attrs["fillcolor"] = "red"
+	}
+	sg.nodes[name] = node
+	if _, ok := sg.clusters[cluster]; !ok {
+		sg.clusters[cluster] = &svgCluster{name: cluster}
+	}
+	sg.clusters[cluster].nodes = append(sg.clusters[cluster].nodes, node)
+	return node
+}
+
+// addStatement adds the nodes and the edges of the DOT statement to the
+// graph, as in a strict graph i.e., an edge specified more than once is added
+// only once, and its attributes are merged.
+func (sg *svgGraph) addStatement(stmt, cluster string) {
+	groups, attrs := parseDotStatement(stmt)
+	if len(groups) == 1 {
+		for _, name := range groups[0] {
+			node := sg.addNode(name, cluster)
+			for key, val := range attrs {
+				node.attrs[key] = val
+			}
+		}
+		return
+	}
+	for gIdx := 1; gIdx < len(groups); gIdx++ {
+		for _, from := range groups[gIdx-1] {
+			for _, to := range groups[gIdx] {
+				sg.addNode(from, cluster)
+				toNode := sg.addNode(to, cluster)
+				edge, ok := sg.edgeIdx[[2]string{from, to}]
+				if !ok {
+					edge = &svgEdge{from: from, to: to, attrs: map[string]string{}}
+					sg.edgeIdx[[2]string{from, to}] = edge
+					sg.edges = append(sg.edges, edge)
+					toNode.requires = append(toNode.requires, from)
+				}
+				for key, val := range attrs {
+					edge.attrs[key] = val
+				}
+			}
+		}
+	}
+}
+
+// getSVGGraph returns the graph parsed from the subgraphs and the cross type
+// edges.
+func getSVGGraph() *svgGraph {
+	sg := &svgGraph{
+		nodes:    map[string]*svgNode{},
+		edgeIdx:  map[[2]string]*svgEdge{},
+		clusters: map[string]*svgCluster{},
+	}
+	subgraphs := []string{}
+	g.subgraph.Range(func(name interface{}, _ interface{}) bool {
+		subgraphs = append(subgraphs, name.(string))
+		return true
+	})
+	sort.Strings(subgraphs)
+	for _, name := range subgraphs {
+		rows, _ := g.subgraph.Load(name)
+		for _, row := range rows.([]string) {
+			sg.addStatement(row, name)
+		}
+	}
+	crossTypeEdges := []string{}
+	g.crossTypeEdges.Range(func(edge interface{}, _ interface{}) bool {
+		crossTypeEdges = append(crossTypeEdges, edge.(string))
+		return true
+	})
+	sort.Strings(crossTypeEdges)
+	for _, edge := range crossTypeEdges {
+		sg.addStatement(edge, "")
+	}
+	return sg
+}
+
+// getOrderedClusters returns the clusters such that the clusters having the
+// plugins required by the plugins of another cluster are before it. The
+// nodes outside the clusters are first, as they're the referenced plugins.
+func (sg *svgGraph) getOrderedClusters() []*svgCluster {
+	names := []string{}
+	for name := range sg.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pending := map[string]map[string]bool{}
+	for _, name := range names {
+		pending[name] = map[string]bool{}
+	}
+	for _, edge := range sg.edges {
+		from, to := sg.nodes[edge.from].cluster, sg.nodes[edge.to].cluster
+		if from != to {
+			pending[to][from] = true
+		}
+	}
+	ordered := []*svgCluster{}
+	for len(ordered) != len(names) {
+		// INFO: Choose the first cluster not requiring the remaining
+		// 	clusters, or the first remaining cluster when the clusters require
+		// 	each other.
+		next := ""
+		for _, name := range names {
+			if pending[name] == nil {
+				continue
+			}
+			if next == "" {
+				next = name
+			}
+			if len(pending[name]) == 0 {
+				next = name
+				break
+			}
+		}
+		ordered = append(ordered, sg.clusters[next])
+		delete(pending, next)
+		for _, deps := range pending {
+			delete(deps, next)
+		}
+	}
+	return ordered
+}
+
+// layoutCluster assigns the nodes of the cluster to layers such that each
+// node is below the nodes of the cluster it requires, orders the nodes of each
+// layer to reduce the edge crossings, and positions them relative to the
+// cluster.
+func (sg *svgGraph) layoutCluster(cluster *svgCluster) {
+	sort.Slice(cluster.nodes, func(i, j int) bool {
+		return cluster.nodes[i].name < cluster.nodes[j].name
+	})
+	inCluster := func(name string) bool {
+		return sg.nodes[name].cluster == cluster.name
+	}
+	// INFO: Assign the layers using the longest path from the nodes not
+	// 	requiring any node, while the nodes forming a cycle are placed below
+	// 	the nodes they require that are already placed.
+	placed := map[string]bool{}
+	for len(placed) != len(cluster.nodes) {
+		progress := false
+		for _, node := range cluster.nodes {
+			if placed[node.name] {
+				continue
+			}
+			ready := true
+			for _, rs := range node.requires {
+				if inCluster(rs) && !placed[rs] && rs != node.name {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			placed[node.name], progress = true, true
+			node.layer = 0
+			for _, rs := range node.requires {
+				if inCluster(rs) && rs != node.name && sg.nodes[rs].layer+1 > node.layer {
+					node.layer = sg.nodes[rs].layer + 1
+				}
+			}
+		}
+		if progress {
+			continue
+		}
+		for _, node := range cluster.nodes {
+			if placed[node.name] {
+				continue
+			}
+			placed[node.name] = true
+			node.layer = 0
+			for _, rs := range node.requires {
+				if placed[rs] && inCluster(rs) && rs != node.name && sg.nodes[rs].layer+1 > node.layer {
+					node.layer = sg.nodes[rs].layer + 1
+				}
+			}
+			break
+		}
+	}
+
+	layers := [][]*svgNode{}
+	for _, node := range cluster.nodes {
+		for len(layers) <= node.layer {
+			layers = append(layers, []*svgNode{})
+		}
+		layers[node.layer] = append(layers[node.layer], node)
+	}
+	// INFO: Order the nodes of each layer by the average position of the
+	// 	nodes they require (i.e., barycenter heuristic).
+	position := map[string]int{}
+	for _, layer := range layers {
+		for idx, node := range layer {
+			position[node.name] = idx
+		}
+	}
+	for lIdx := 1; lIdx < len(layers); lIdx++ {
+		layer := layers[lIdx]
+		barycenter := map[string]float64{}
+		for idx, node := range layer {
+			barycenter[node.name] = float64(idx)
+			sum, count := 0, 0
+			for _, rs := range node.requires {
+				if inCluster(rs) && sg.nodes[rs].layer < lIdx {
+					sum += position[rs]
+					count++
+				}
+			}
+			if count != 0 {
+				barycenter[node.name] = float64(sum) / float64(count)
+			}
+		}
+		sort.SliceStable(layer, func(i, j int) bool {
+			return barycenter[layer[i].name] < barycenter[layer[j].name]
+		})
+		for idx, node := range layer {
+			position[node.name] = idx
+		}
+	}
+
+	// INFO: Size the nodes as per their labels, and center the layers.
+	layerWidths := make([]float64, len(layers))
+	y := float64(svgClusterPadding + svgClusterLabel)
+	for lIdx, layer := range layers {
+		layerHeight := 0.0
+		for _, node := range layer {
+			lines := getSVGNodeLabelLines(node)
+			maxLen := 0
+			for _, line := range lines {
+				if len(line) > maxLen {
+					maxLen = len(line)
+				}
+			}
+			node.width = float64(maxLen*svgCharWidth + 30)
+			node.height = float64(len(lines)*svgLineHeight + 14)
+			if layerWidths[lIdx] != 0 {
+				layerWidths[lIdx] += svgNodeGap
+			}
+			node.x = layerWidths[lIdx]
+			node.y = y
+			layerWidths[lIdx] += node.width
+			if node.height > layerHeight {
+				layerHeight = node.height
+			}
+		}
+		y += layerHeight + svgLayerGap
+	}
+	cluster.width = 0
+	for _, width := range layerWidths {
+		if width > cluster.width {
+			cluster.width = width
+		}
+	}
+	for lIdx, layer := range layers {
+		for _, node := range layer {
+			node.x += svgClusterPadding + (cluster.width-layerWidths[lIdx])/2
+		}
+	}
+	cluster.width += 2 * svgClusterPadding
+	cluster.height = y - svgLayerGap + svgClusterPadding
+}
+
+// getSVGNodeLabelLines returns the lines of the label of the node, which is
+// the node name when the label is not specified.
+func getSVGNodeLabelLines(node *svgNode) []string {
+	label, ok := node.attrs["label"]
+	if !ok {
+		label = node.name
+	}
+	lines := strings.Split(label, `\n`)
+	for idx := range lines {
+		lines[idx] = strings.Replace(lines[idx], `\"`, `"`, -1)
+	}
+	return lines
+}
+
+// getSVGStroke returns the SVG stroke attributes for the DOT attributes.
+func getSVGStroke(attrs map[string]string) string {
+	color := attrs["color"]
+	if color == "" {
+		color = "black"
+	}
+	penwidth := attrs["penwidth"]
+	if _, err := strconv.ParseFloat(penwidth, 64); err != nil {
+		penwidth = "1"
+	}
+	stroke := fmt.Sprintf(`stroke="%s" stroke-width="%s"`, html.EscapeString(color), penwidth)
+	if strings.Contains(attrs["style"], "dashed") {
+		stroke += ` stroke-dasharray="5,3"`
+	}
+	return stroke
+}
+
+// renderSVG returns the graph as an SVG image.
+func (sg *svgGraph) renderSVG() string {
+	clusters := sg.getOrderedClusters()
+	width := 0.0
+	for _, cluster := range clusters {
+		sg.layoutCluster(cluster)
+		if cluster.width > width {
+			width = cluster.width
+		}
+	}
+	// INFO: Stack the clusters vertically in their order, as the plugins of
+	// 	a type are run after the plugins of the types they require.
+	y := float64(svgMargin)
+	for _, cluster := range clusters {
+		cluster.x = svgMargin + (width-cluster.width)/2
+		cluster.y = y
+		for _, node := range cluster.nodes {
+			node.x += cluster.x
+			node.y += cluster.y
+		}
+		y += cluster.height + svgClusterGap
+	}
+	height := y - svgClusterGap + svgMargin
+	width += 2 * svgMargin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`xmlns:xlink="http://www.w3.org/1999/xlink" width="%.0f" height="%.0f" `+
+		`font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	// INFO: Define an arrow head for each color of the edges.
+	colors := []string{}
+	for _, edge := range sg.edges {
+		if color := edge.attrs["color"]; color != "" && !containsString(colors, color) {
+			colors = append(colors, color)
+		}
+	}
+	sort.Strings(colors)
+	buf.WriteString("<defs>\n")
+	for _, color := range append([]string{"black"}, colors...) {
+		fmt.Fprintf(&buf, `<marker id="arrow-%s" viewBox="0 0 10 10" refX="10" refY="5" `+
+			`markerWidth="8" markerHeight="8" orient="auto-start-reverse">`+
+			`<path d="M 0 0 L 10 5 L 0 10 z" fill="%s"/></marker>`+"\n",
+			html.EscapeString(color), html.EscapeString(color))
+	}
+	buf.WriteString("</defs>\n")
+
+	for _, cluster := range clusters {
+		if cluster.name == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" `+
+			`fill="none" stroke="black"/>`+"\n", cluster.x, cluster.y, cluster.width, cluster.height)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" font-size="18">%s plugins</text>`+"\n",
+			cluster.x+cluster.width/2, cluster.y+svgClusterPadding+10, html.EscapeString(cluster.name))
+	}
+
+	for _, edge := range sg.edges {
+		from, to := sg.nodes[edge.from], sg.nodes[edge.to]
+		x1, y1 := from.x+from.width/2, from.y+from.height
+		x2, y2 := to.x+to.width/2, to.y
+		dy := (y2 - y1) / 2
+		if dy < svgLayerGap/2 {
+			dy = svgLayerGap / 2
+		}
+		color := edge.attrs["color"]
+		if color == "" {
+			color = "black"
+		}
+		fmt.Fprintf(&buf, `<path d="M %.1f %.1f C %.1f %.1f %.1f %.1f %.1f %.1f" fill="none" %s `+
+			`marker-end="url(#arrow-%s)"/>`+"\n", x1, y1, x1, y1+dy, x2, y2-dy, x2, y2,
+			getSVGStroke(edge.attrs), html.EscapeString(color))
+	}
+
+	for _, cluster := range clusters {
+		for _, node := range cluster.nodes {
+			url := node.attrs["URL"]
+			if url != "" {
+				fmt.Fprintf(&buf, `<a xlink:href="%s">`, html.EscapeString(url))
+			}
+			fill := "none"
+			if strings.Contains(node.attrs["style"], "filled") && node.attrs["fillcolor"] != "" {
+				fill = node.attrs["fillcolor"]
+			}
+			fmt.Fprintf(&buf, `<g><title>%s</title>`, html.EscapeString(node.name))
+			if node.cluster == "" {
+				fmt.Fprintf(&buf, `<ellipse cx="%.1f" cy="%.1f" rx="%.1f" ry="%.1f" fill="%s" %s/>`,
+					node.x+node.width/2, node.y+node.height/2, node.width/2, node.height/2,
+					html.EscapeString(fill), getSVGStroke(node.attrs))
+			} else {
+				// NOTE: Hexagon, same as the node shape of the subgraphs in
+				// 	the DOT file.
+				x, y, w, h := node.x, node.y, node.width, node.height
+				fmt.Fprintf(&buf, `<polygon points="%.1f,%.1f %.1f,%.1f %.1f,%.1f %.1f,%.1f %.1f,%.1f %.1f,%.1f" `+
+					`fill="%s" %s/>`, x, y+h/2, x+10, y, x+w-10, y, x+w, y+h/2, x+w-10, y+h, x+10, y+h,
+					html.EscapeString(fill), getSVGStroke(node.attrs))
+			}
+			lines := getSVGNodeLabelLines(node)
+			for lIdx, line := range lines {
+				fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`,
+					node.x+node.width/2, node.y+7+float64(lIdx+1)*svgLineHeight-4,
+					html.EscapeString(line))
+			}
+			buf.WriteString("</g>")
+			if url != "" {
+				buf.WriteString("</a>")
+			}
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString("</svg>\n")
+	return buf.String()
+}
+
+// renderGraph renders the graph as an SVG image in the svg file without
+// using the graphviz dot command.
+func renderGraph(svgFile string) error {
+	return os.WriteFile(svgFile, []byte(getSVGGraph().renderSVG()), 0644)
+}
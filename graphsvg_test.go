@@ -0,0 +1,169 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseDotStatement(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name       string
+		stmt       string
+		wantGroups [][]string
+		wantAttrs  map[string]string
+	}{
+		{
+			name:       "Node",
+			stmt:       `"A/a.pre"`,
+			wantGroups: [][]string{{"A/a.pre"}},
+			wantAttrs:  map[string]string{},
+		},
+		{
+			name:       "Node with attributes",
+			stmt:       `"A/a.pre" [label="Applying \"A\", [a]\n[tag]",style=filled,fillcolor=lightgrey,URL="./A/a.pre"]`,
+			wantGroups: [][]string{{"A/a.pre"}},
+			wantAttrs: map[string]string{"label": `Applying \"A\", [a]\n[tag]`, "style": "filled",
+				"fillcolor": "lightgrey", "URL": "./A/a.pre"},
+		},
+		{
+			name:       "Edges from multiple nodes",
+			stmt:       `"B/b.pre", "C/c.pre" -> "A/a.pre"`,
+			wantGroups: [][]string{{"B/b.pre", "C/c.pre"}, {"A/a.pre"}},
+			wantAttrs:  map[string]string{},
+		},
+		{
+			name:       "Edges to multiple nodes with attributes",
+			stmt:       `"A/a.pre" -> "B/b.pre", "C/c.pre" [color=red,penwidth=3]`,
+			wantGroups: [][]string{{"A/a.pre"}, {"B/b.pre", "C/c.pre"}},
+			wantAttrs:  map[string]string{"color": "red", "penwidth": "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, attrs := parseDotStatement(tt.stmt)
+			if !reflect.DeepEqual(groups, tt.wantGroups) {
+				t.Errorf("parseDotStatement() groups = %v, want %v", groups, tt.wantGroups)
+			}
+			if !reflect.DeepEqual(attrs, tt.wantAttrs) {
+				t.Errorf("parseDotStatement() attrs = %v, want %v", attrs, tt.wantAttrs)
+			}
+		})
+	}
+}
+
+func Test_layoutCluster(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	initGraph("svg0", Plugins{{Name: "X/x.svg0", Description: "X"}})
+	initGraph("svg1", Plugins{
+		{Name: "A/a.svg1", Description: "A"},
+		{Name: "B/b.svg1", Description: "B", Requires: []string{"A/a.svg1"}},
+		{Name: "C/c.svg1", Description: "C", Requires: []string{"A/a.svg1", "B/b.svg1"}},
+		{Name: "D/d.svg1", Description: "D", Requires: []string{"X/x.svg0"}},
+	})
+	initGraph("svg2", Plugins{
+		{Name: "E/e.svg2", Description: "E", Requires: []string{"F/f.svg2"}},
+		{Name: "F/f.svg2", Description: "F", Requires: []string{"E/e.svg2"}},
+	})
+	updateGraph("svg1", "A/a.svg1", dStatusOk, "A/a.svg1.log")
+
+	sg := getSVGGraph()
+	clusters := []string{}
+	for _, cluster := range sg.getOrderedClusters() {
+		if strings.HasPrefix(cluster.name, "svg") {
+			clusters = append(clusters, cluster.name)
+		}
+	}
+	if want := []string{"svg0", "svg1", "svg2"}; !reflect.DeepEqual(clusters, want) {
+		t.Errorf("getOrderedClusters() = %v, want %v", clusters, want)
+	}
+
+	tests := []struct {
+		cluster    string
+		wantLayers map[string]int
+	}{
+		{
+			cluster: "svg1",
+			wantLayers: map[string]int{"A/a.svg1": 0, "B/b.svg1": 1, "C/c.svg1": 2,
+				"D/d.svg1": 0},
+		},
+		{
+			cluster:    "svg2",
+			wantLayers: map[string]int{"E/e.svg2": 0, "F/f.svg2": 1},
+		},
+	}
+	for _, tt := range tests {
+		sg.layoutCluster(sg.clusters[tt.cluster])
+		layers := map[string]int{}
+		for _, node := range sg.clusters[tt.cluster].nodes {
+			layers[node.name] = node.layer
+			if node.x < 0 || node.x+node.width > sg.clusters[tt.cluster].width {
+				t.Errorf("layoutCluster() node %s is outside the cluster", node.name)
+			}
+		}
+		if !reflect.DeepEqual(layers, tt.wantLayers) {
+			t.Errorf("layoutCluster(%s) = %v, want %v", tt.cluster, layers, tt.wantLayers)
+		}
+	}
+
+	aNode := sg.nodes["A/a.svg1"]
+	if aNode.attrs["fillcolor"] != getStatusColor(dStatusOk) || aNode.attrs["URL"] != "A/a.svg1.log" ||
+		!reflect.DeepEqual(getSVGNodeLabelLines(aNode), []string{"A"}) {
+		t.Errorf("getSVGGraph() A/a.svg1 attributes = %v, want the status color and log URL", aNode.attrs)
+	}
+}
+
+func Test_renderGraph(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	defer func(renderer string) { graphRenderer = renderer }(graphRenderer)
+	graphRenderer = graphRendererBuiltin
+	initGraph("svg3", Plugins{
+		{Name: "A/a.svg3", Description: `A's "description"`, Tags: []string{"x"}},
+		{Name: "B/b.svg3", Description: "B", Requires: []string{"A/a.svg3", "C/c.svg3"}},
+	})
+	if err := updateGraph("svg3", "A/a.svg3", dStatusFail, "A/a.svg3.log"); err != nil {
+		t.Fatalf("updateGraph() error = %v", err)
+	}
+
+	contents, err := os.ReadFile(getImagePath())
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	decoder := xml.NewDecoder(strings.NewReader(string(contents)))
+	for {
+		if _, err = decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("renderGraph() generated invalid SVG. Error: %v", err)
+		}
+	}
+	for _, s := range []string{
+		"svg3 plugins",
+		"A&#39;s &#34;description&#34;",
+		"[x]",
+		`fill="` + getStatusColor(dStatusFail) + `"`,
+		`xlink:href="A/a.svg3.log"`,
+		"<title>C/c.svg3</title>",
+	} {
+		if !strings.Contains(string(contents), s) {
+			t.Errorf("renderGraph() image doesn't contain %s", s)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm history is used for recording each run in the run history, and
+// for displaying the recorded runs.
+package pm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+// RunArtifacts are the files generated by a run.
+type RunArtifacts struct {
+	LogFile   string `yaml:",omitempty" json:",omitempty"`
+	DotFile   string `yaml:",omitempty" json:",omitempty"`
+	ImageFile string `yaml:",omitempty" json:",omitempty"`
+	// TimelineFile is the timeline image of the run.
+	TimelineFile string `yaml:",omitempty" json:",omitempty"`
+}
+
+// RunRecord is the record of a run in the run history.
+type RunRecord struct {
+	RunID     string
+	Type      string
+	Status    string
+	StartTime time.Time
+	EndTime   time.Time
+	Options   RunOptions
+	Artifacts RunArtifacts
+	// Result is the run status, which is not included while listing the
+	// 	runs.
+	Result *RunStatus `yaml:",omitempty" json:",omitempty"`
+}
+
+// getHistoryDir returns the directory having the run records.
+func getHistoryDir() string {
+	return config.GetStateDir() + "history" + string(os.PathSeparator)
+}
+
+// getRunRecordFile returns the file having the record of the run.
+func getRunRecordFile(runID string) string {
+	return getHistoryDir() + runID + ".yaml"
+}
+
+// validateRunID returns an error when the run ID has a path separator or
+// "..", so that the run records are read and written only in the history dir.
+func validateRunID(runID string) error {
+	if strings.ContainsAny(runID, `/\`) || strings.Contains(runID, "..") {
+		return fmt.Errorf("Invalid run ID '%s'. The run ID must not have '/', '\\' or '..'.", runID)
+	}
+	return nil
+}
+
+// getRunArtifacts returns the files generated by the current run.
+func getRunArtifacts() RunArtifacts {
+	artifacts := RunArtifacts{LogFile: logger.GetLogFilePath()}
+	if g.fileNoExt != "" {
+		artifacts.DotFile, _ = filepath.Abs(getDotFilePath())
+		artifacts.ImageFile, _ = filepath.Abs(getImagePath())
+		if _, err := os.Stat(getTimelinePath()); err == nil {
+			artifacts.TimelineFile, _ = filepath.Abs(getTimelinePath())
+		}
+	}
+	return artifacts
+}
+
+// saveRunRecord records the run in the run history.
+//
+//	NOTE: Failing to record the run doesn't fail the run.
+func saveRunRecord(result *RunStatus, runOptions RunOptions, startTime time.Time) {
+	if result.RunID == "" {
+		return
+	}
+	if err := validateRunID(result.RunID); err != nil {
+		logger.Warning.Printf("Not recording the run. Error: %s", err.Error())
+		return
+	}
+	record := RunRecord{
+		RunID:     result.RunID,
+		Type:      result.Type,
+		Status:    result.Status,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		Options:   runOptions,
+		Artifacts: getRunArtifacts(),
+		Result:    result,
+	}
+	bytes, err := yaml.Marshal(record)
+	if err != nil {
+		logger.Warning.Printf("Failed to marshal the run record. Error: %s", err.Error())
+		return
+	}
+	if err = os.MkdirAll(getHistoryDir(), 0755); err != nil {
+		logger.Warning.Printf("Failed to create history dir %s. Error: %s",
+			getHistoryDir(), err.Error())
+		return
+	}
+	recordFile := getRunRecordFile(result.RunID)
+	if err = os.WriteFile(recordFile, bytes, 0644); err != nil {
+		logger.Warning.Printf("Failed to record the run in %s. Error: %s",
+			recordFile, err.Error())
+		return
+	}
+	logger.Info.Printf("Run %s is recorded in %s.", result.RunID, recordFile)
+}
+
+// loadRunRecord returns the recorded run.
+func loadRunRecord(runID string) (RunRecord, error) {
+	var record RunRecord
+	bytes, err := os.ReadFile(filepath.Clean(getRunRecordFile(runID)))
+	if err != nil {
+		return record, err
+	}
+	err = yaml.Unmarshal(bytes, &record)
+	return record, err
+}
+
+// loadRunRecords returns the recorded runs of the plugin type (or all types
+// when not specified) in the order of their start time. When last is
+// specified, only the last runs are returned.
+//
+//	NOTE: The runs of multiple plugin types as phases are included for each
+//	of their types.
+func loadRunRecords(pluginType string, last int) ([]RunRecord, error) {
+	records := []RunRecord{}
+	entries, err := os.ReadDir(getHistoryDir())
+	if os.IsNotExist(err) {
+		return records, nil
+	} else if err != nil {
+		return records, logger.ConsoleError.PrintNReturnError(
+			"Failed to read history dir %s. Error: %s", getHistoryDir(), err.Error())
+	}
+	for _, entry := range entries {
+		runID := strings.TrimSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || runID == entry.Name() {
+			continue
+		}
+		record, lerr := loadRunRecord(runID)
+		if lerr != nil {
+			logger.Warning.Printf("Failed to read the record of run %s. Error: %s",
+				runID, lerr.Error())
+			continue
+		}
+		if pluginType != "" && !containsString(getPhaseTypes(record.Type), pluginType) {
+			continue
+		}
+		record.Result = nil
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartTime.Before(records[j].StartTime)
+	})
+	if last > 0 && len(records) > last {
+		records = records[len(records)-last:]
+	}
+	return records, nil
+}
+
+// History displays the recorded runs of the plugin type (or all types when
+// not specified), and returns them.
+func History(pluginType string, last int) ([]RunRecord, error) {
+	logger.Debug.Printf("Entering History(%s, %d)...", pluginType, last)
+	defer logger.Debug.Println("Exiting History")
+
+	records, err := loadRunRecords(pluginType, last)
+	if err != nil {
+		return records, err
+	}
+	if len(records) == 0 {
+		logger.ConsoleInfo.Printf("No runs are recorded in %s.", getHistoryDir())
+		return records, nil
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN ID\tTYPE\tSTATUS\tSTART TIME\tDURATION")
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", record.RunID, record.Type, record.Status,
+			record.StartTime.Format(time.RFC3339),
+			record.EndTime.Sub(record.StartTime).Round(time.Millisecond))
+	}
+	w.Flush()
+	logger.ConsoleInfo.Printf("%s", strings.TrimSuffix(buf.String(), "\n"))
+	return records, nil
+}
+
+// ShowRun displays the recorded run along with the status of its plugins, and
+// returns the record.
+func ShowRun(runID string) (RunRecord, error) {
+	logger.Debug.Printf("Entering ShowRun(%s)...", runID)
+	defer logger.Debug.Println("Exiting ShowRun")
+
+	if runID == "" {
+		return RunRecord{}, logger.ConsoleError.PrintNReturnError(
+			"No run specified. Specify the run ID listed by the history command.")
+	}
+	if err := validateRunID(runID); err != nil {
+		return RunRecord{}, logger.ConsoleError.PrintNReturnError("%s", err.Error())
+	}
+	record, err := loadRunRecord(runID)
+	if os.IsNotExist(err) {
+		return record, logger.ConsoleError.PrintNReturnError(
+			"Run %s is not present in %s.", runID, getHistoryDir())
+	} else if err != nil {
+		return record, logger.ConsoleError.PrintNReturnError(
+			"Failed to read the record of run %s. Error: %s", runID, err.Error())
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Run ID:\t%s\n", record.RunID)
+	fmt.Fprintf(w, "Type:\t%s\n", record.Type)
+	fmt.Fprintf(w, "Status:\t%s\n", record.Status)
+	fmt.Fprintf(w, "Start Time:\t%s\n", record.StartTime.Format(time.RFC3339))
+	fmt.Fprintf(w, "Duration:\t%s\n", record.EndTime.Sub(record.StartTime).Round(time.Millisecond))
+	for _, artifact := range [][2]string{
+		{"Log File", record.Artifacts.LogFile},
+		{"Dot File", record.Artifacts.DotFile},
+		{"Image File", record.Artifacts.ImageFile},
+		{"Timeline File", record.Artifacts.TimelineFile},
+	} {
+		if artifact[1] != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", artifact[0], artifact[1])
+		}
+	}
+	if record.Result != nil {
+		phases := record.Result.Phases
+		if len(phases) == 0 {
+			phases = []RunStatus{*record.Result}
+		}
+		fmt.Fprintln(w, "\nPLUGIN\tSTATUS\tREASON")
+		for _, phase := range phases {
+			for _, pInfo := range phase.Plugins {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", pInfo.Name, pInfo.Status, pInfo.Reason)
+			}
+		}
+	}
+	w.Flush()
+	logger.ConsoleInfo.Printf("%s", strings.TrimSuffix(buf.String(), "\n"))
+	return record, nil
+}
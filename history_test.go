@@ -0,0 +1,86 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func TestHistory(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.pre":  "Description=A\nExecStart=/bin/echo A\n",
+		"B/b.post": "Description=B\nExecStart=/bin/false\n",
+	})
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+
+	records, err := History("", 0)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("History() = %+v, %v, want no runs", records, err)
+	}
+	for _, run := range []struct{ runID, pluginType string }{
+		{"run-1", "pre"},
+		{"run-2", "post"},
+		{"run-3", "pre,post"},
+		{"run-4", "pre"},
+	} {
+		result := RunStatus{}
+		RunFromLibrary(&result, run.pluginType, RunOptions{Library: library, RunID: run.runID})
+	}
+
+	tests := []struct {
+		name       string
+		pluginType string
+		last       int
+		want       []string
+	}{
+		{name: "All runs", want: []string{"run-1", "run-2", "run-3", "run-4"}},
+		{name: "Runs of a type", pluginType: "pre", want: []string{"run-1", "run-3", "run-4"}},
+		{name: "Last runs of a type", pluginType: "post", last: 1, want: []string{"run-3"}},
+		{name: "Runs of an unknown type", pluginType: "other", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := History(tt.pluginType, tt.last)
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+			got := []string{}
+			for _, record := range records {
+				got = append(got, record.RunID)
+				if record.Result != nil {
+					t.Errorf("History() run %s has Result, want none", record.RunID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("History() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	record, err := ShowRun("run-3")
+	if err != nil {
+		t.Fatalf("ShowRun() error = %v", err)
+	}
+	if record.Type != "pre,post" || record.Status != dStatusFail || record.Options.Library != library ||
+		record.Result == nil || len(record.Result.Phases) != 2 || record.EndTime.Before(record.StartTime) {
+		t.Errorf("ShowRun() = %+v, want the failed run of pre,post phases", record)
+	}
+	if _, err = ShowRun("run-5"); err == nil {
+		t.Errorf("ShowRun() of a run that isn't recorded, want error")
+	}
+	for _, runID := range []string{"../history/run-1", "..", "sub/run-1"} {
+		if _, err = ShowRun(runID); err == nil {
+			t.Errorf("ShowRun(%s) of an invalid run ID, want error", runID)
+		}
+	}
+}
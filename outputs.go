@@ -0,0 +1,152 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm outputs is used for passing the key=value outputs of the plugins
+// to the plugins requiring them.
+package pm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// outputEnv is the env value having the file, to which the plugin could
+	// 	write its outputs as "<key>=<value>" lines.
+	outputEnv = "PM_OUTPUT"
+	// inputsEnv is the env value having the JSON file with the outputs of
+	// 	the required plugins i.e., {"<plugin>": {"<key>": "<value>"}}.
+	inputsEnv = "PM_INPUTS"
+	// inputEnvPrefix is the prefix of the env values having the outputs of
+	// 	the required plugins i.e., "PM_IN_<plugin>_<key>".
+	inputEnvPrefix = "PM_IN_"
+)
+
+// invalidEnvChars matches the characters that are not allowed in env names.
+var invalidEnvChars = regexp.MustCompile("[^A-Za-z0-9_]")
+
+// getInputEnvName returns the name of the env value having the output of the
+// required plugin (Ex: "PM_IN_A_a_preupgrade_device" for the "device" output
+// of "A/a.preupgrade" plugin).
+func getInputEnvName(pName, key string) string {
+	return inputEnvPrefix + invalidEnvChars.ReplaceAllString(pName, "_") + "_" +
+		invalidEnvChars.ReplaceAllString(key, "_")
+}
+
+// parsePluginOutputs returns the "<key>=<value>" outputs, along with the
+// lines that are not in the expected format.
+//
+//	NOTE: Empty lines and comments (i.e., lines starting with "#") are
+//	ignored. When a key is written multiple times, the last value is used.
+func parsePluginOutputs(contents string) (map[string]string, []string) {
+	outputs := map[string]string{}
+	invalidLines := []string{}
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			invalidLines = append(invalidLines, line)
+			continue
+		}
+		outputs[key] = strings.TrimSpace(val)
+	}
+	return outputs, invalidLines
+}
+
+// getPluginUpstreams returns the plugins required by each plugin, whose
+// outputs are passed to the plugin. The required plugins include the plugins
+// of the earlier phases, as well as the plugin references.
+func getPluginUpstreams(nPInfo Plugins, completedPlugins Plugins) map[string][]string {
+	upstreams := map[string][]string{}
+	for _, pInfo := range nPInfo {
+		upstreams[pInfo.Name] = append([]string{}, pInfo.Requires...)
+		for _, cInfo := range completedPlugins {
+			if containsString(cInfo.RequiredBy, pInfo.Name) &&
+				!containsString(upstreams[pInfo.Name], cInfo.Name) {
+				upstreams[pInfo.Name] = append(upstreams[pInfo.Name], cInfo.Name)
+			}
+		}
+	}
+	return upstreams
+}
+
+// getPluginInputs returns the outputs of the required plugins, which are
+// either run in the current run, or resolved using the resolver.
+func getPluginInputs(upstreams []string, pluginsStatus map[string]Plugin,
+	resolver pluginRefResolver) map[string]map[string]string {
+	inputs := map[string]map[string]string{}
+	for _, rs := range upstreams {
+		pInfo, ok := pluginsStatus[rs]
+		if isPluginRef(rs) {
+			pInfo, ok = resolver.resolve(rs)
+		} else if !ok {
+			pInfo, ok = resolver.completed[rs]
+		}
+		if ok && len(pInfo.Outputs) != 0 {
+			inputs[pInfo.Name] = pInfo.Outputs
+		}
+	}
+	return inputs
+}
+
+// getInputsEnv returns the env values having the inputs of the plugin.
+//
+//	NOTE: The outputs having the same env name, as their names differ only in
+//	the characters not allowed in env names (Ex: "a-b" and "a.b"), are
+//	reported as an error instead of passing one of them.
+func getInputsEnv(inputs map[string]map[string]string) (map[string]string, error) {
+	env := map[string]string{}
+	// envSources tracks the output passed in each env value to detect the
+	// 	outputs having the same env name.
+	envSources := map[string]string{}
+	pNames := []string{}
+	for pName := range inputs {
+		pNames = append(pNames, pName)
+	}
+	sort.Strings(pNames)
+	for _, pName := range pNames {
+		keys := []string{}
+		for key := range inputs[pName] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			envName := getInputEnvName(pName, key)
+			source := fmt.Sprintf("'%s' output of %s", key, pName)
+			if prevSource, ok := envSources[envName]; ok {
+				return env, fmt.Errorf("Both %s and %s are passed as %s env value.",
+					prevSource, source, envName)
+			}
+			envSources[envName] = source
+			env[envName] = inputs[pName][key]
+		}
+	}
+	return env, nil
+}
+
+// createPluginIOFiles creates the output file of the plugin, and the inputs
+// file having the outputs of its required plugins in a temporary directory,
+// and returns the directory.
+func createPluginIOFiles(inputs map[string]map[string]string) (string, error) {
+	ioDir, err := os.MkdirTemp("", "pm-io-")
+	if err != nil {
+		return ioDir, err
+	}
+	if err = os.WriteFile(filepath.Join(ioDir, "output"), []byte{}, 0600); err != nil {
+		return ioDir, err
+	}
+	bytes, err := json.Marshal(inputs)
+	if err != nil {
+		return ioDir, err
+	}
+	err = os.WriteFile(filepath.Join(ioDir, "inputs.json"), bytes, 0600)
+	return ioDir, err
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func Test_parsePluginOutputs(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name             string
+		contents         string
+		wantOutputs      map[string]string
+		wantInvalidLines []string
+	}{
+		{
+			name:             "No outputs",
+			contents:         "",
+			wantOutputs:      map[string]string{},
+			wantInvalidLines: []string{},
+		},
+		{
+			name:             "Outputs with comments and invalid lines",
+			contents:         "# Devices\ndevice = /dev/sda\n\nopts=a=b\ninvalid\n=empty\ndevice=/dev/sdb\n",
+			wantOutputs:      map[string]string{"device": "/dev/sdb", "opts": "a=b"},
+			wantInvalidLines: []string{"invalid", "=empty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOutputs, gotInvalidLines := parsePluginOutputs(tt.contents)
+			if !reflect.DeepEqual(gotOutputs, tt.wantOutputs) {
+				t.Errorf("parsePluginOutputs() outputs = %v, want %v", gotOutputs, tt.wantOutputs)
+			}
+			if !reflect.DeepEqual(gotInvalidLines, tt.wantInvalidLines) {
+				t.Errorf("parsePluginOutputs() invalid lines = %v, want %v",
+					gotInvalidLines, tt.wantInvalidLines)
+			}
+		})
+	}
+	if got := getInputEnvName("A/a-b.preupgrade", "dev.path"); got != "PM_IN_A_a_b_preupgrade_dev_path" {
+		t.Errorf("getInputEnvName() = %s, want PM_IN_A_a_b_preupgrade_dev_path", got)
+	}
+	_, err := getInputsEnv(map[string]map[string]string{"A/a.test": {"a-b": "1", "a.b": "2"}})
+	want := "Both 'a-b' output of A/a.test and 'a.b' output of A/a.test are passed as PM_IN_A_a_test_a_b env value."
+	if err == nil || err.Error() != want {
+		t.Errorf("getInputsEnv() error = %v, want %v", err, want)
+	}
+}
+
+func TestRunFromLibrary_outputs(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		pluginType  string
+		wantOutputs map[string]map[string]string
+		wantStdOut  map[string][]string
+	}{
+		{
+			name: "Outputs passed to the required plugins",
+			files: map[string]string{
+				"A/a.test": "Description=A\nExecStart=${PM_LIBRARY}/A/a.sh\n",
+				"A/a.sh":   "#!/bin/sh\necho device=/dev/sda >> $PM_OUTPUT\necho '# comment' >> $PM_OUTPUT\n",
+				"B/b.test": "Description=B\nExecStart=${PM_LIBRARY}/B/b.sh ${PM_IN_A_a_test_device}\nRequires=A/a.test\n",
+				"B/b.sh":   "#!/bin/sh\necho $1 $PM_IN_A_a_test_device\ncat $PM_INPUTS\n",
+				"C/c.test": "Description=C\nExecStart=${PM_LIBRARY}/C/c.sh\n",
+				"C/c.sh":   "#!/bin/sh\necho \"$PM_IN_A_a_test_device\"\ncat $PM_INPUTS\n",
+				"D/d.test": "Description=D\nExecStart=${PM_LIBRARY}/D/d.sh ${PM_OUTPUT} ${PM_INPUTS}\n",
+				"D/d.sh":   "#!/bin/sh\necho size=1G > $1\ncat $2\n",
+			},
+			pluginType: "test",
+			wantOutputs: map[string]map[string]string{
+				"A/a.test": {"device": "/dev/sda"},
+				"D/d.test": {"size": "1G"},
+			},
+			wantStdOut: map[string][]string{
+				"B/b.test": {"/dev/sda /dev/sda", `{"A/a.test":{"device":"/dev/sda"}}`},
+				"C/c.test": {"", "{}"},
+				"D/d.test": {"{}"},
+			},
+		},
+		{
+			name: "Outputs passed to the plugins of the later phases",
+			files: map[string]string{
+				"A/a.pre":  "Description=A\nExecStart=${PM_LIBRARY}/A/a.sh\nRequiredBy=B/b.post\n",
+				"A/a.sh":   "#!/bin/sh\necho device=/dev/sdb > $PM_OUTPUT\n",
+				"B/b.post": "Description=B\nExecStart=/bin/echo ${PM_IN_A_a_pre_device}\n",
+				"C/c.post": "Description=C\nExecStart=/bin/echo ${PM_IN_A_a_pre_device}\nRequires=A/a.pre\n",
+				"D/d.post": "Description=D\nExecStart=/bin/echo ${PM_IN_A_a_pre_device}\n",
+			},
+			pluginType: "pre,post",
+			wantOutputs: map[string]map[string]string{
+				"A/a.pre": {"device": "/dev/sdb"},
+			},
+			wantStdOut: map[string][]string{
+				"B/b.post": {"/dev/sdb"},
+				"C/c.post": {"/dev/sdb"},
+				"D/d.post": {""},
+			},
+		},
+	}
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := createTestLibrary(t, tt.files)
+			result := RunStatus{}
+			if err := RunFromLibrary(&result, tt.pluginType, RunOptions{Library: library}); err != nil {
+				t.Fatalf("RunFromLibrary() error = %v", err)
+			}
+			pluginsInfo := result.Plugins
+			for _, phase := range result.Phases {
+				pluginsInfo = append(pluginsInfo, phase.Plugins...)
+			}
+			gotOutputs := map[string]map[string]string{}
+			for _, pInfo := range pluginsInfo {
+				if pInfo.Outputs != nil {
+					gotOutputs[pInfo.Name] = pInfo.Outputs
+				}
+				if want, ok := tt.wantStdOut[pInfo.Name]; ok && !reflect.DeepEqual(pInfo.StdOutErr, want) {
+					t.Errorf("Plugin %s StdOutErr = %q, want %q", pInfo.Name, pInfo.StdOutErr, want)
+				}
+			}
+			if !reflect.DeepEqual(gotOutputs, tt.wantOutputs) {
+				t.Errorf("RunFromLibrary() Outputs = %v, want %v", gotOutputs, tt.wantOutputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm phase is used for running multiple plugin types as ordered
+// phases in a single run.
+package pm
+
+import (
+	"fmt"
+	"path/filepath"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+// PhasesFile is the file listing the plugin types to be run as ordered
+// phases.
+//
+//	Example:
+//	phases:
+//	  - preupgrade
+//	  - prereboot
+type PhasesFile struct {
+	Phases []string `yaml:"phases" json:"phases"`
+}
+
+// getPhaseTypes returns the plugin types specified as a comma separated list.
+func getPhaseTypes(pluginType string) []string {
+	return splitCommaSeparated([]string{pluginType})
+}
+
+// readPhasesFile returns the plugin types listed in the phases file.
+func readPhasesFile(phasesFile string) ([]string, error) {
+	var phases PhasesFile
+	contents, err := readFile(filepath.FromSlash(phasesFile))
+	if err != nil {
+		return phases.Phases, logger.ConsoleError.PrintNReturnError(
+			"Failed to read phases file %s. Error: %s", phasesFile, err.Error())
+	}
+	// NOTE: YAML being a superset of JSON, the phases file could be in
+	// 	either of the formats.
+	if err = yaml.Unmarshal([]byte(contents), &phases); err != nil {
+		return phases.Phases, logger.ConsoleError.PrintNReturnError(
+			"Phases file %s is not in expected format. Error: %s", phasesFile, err.Error())
+	}
+	if len(phases.Phases) == 0 {
+		return phases.Phases, logger.ConsoleError.PrintNReturnError(
+			"No phases specified in phases file %s.", phasesFile)
+	}
+	return phases.Phases, nil
+}
+
+// validatePhaseDependencies validates that the plugins don't require the
+// plugins of the later phases.
+func validatePhaseDependencies(phases []RunStatus) error {
+	phaseIndexes := map[string]int{}
+	allPlugins := Plugins{}
+	for phaseIdx, phase := range phases {
+		for _, pInfo := range phase.Plugins {
+			phaseIndexes[pInfo.Name] = phaseIdx
+		}
+		allPlugins = append(allPlugins, phase.Plugins...)
+	}
+	for _, pInfo := range normalizePluginsInfo(allPlugins) {
+		for _, rs := range pInfo.Requires {
+			if rsIdx, ok := phaseIndexes[getPluginRefName(rs)]; ok && rsIdx > phaseIndexes[pInfo.Name] {
+				return logger.ConsoleError.PrintNReturnError(
+					"Plugin %s of %s phase requires %s of later %s phase.",
+					pInfo.Name, phases[phaseIndexes[pInfo.Name]].Type, rs, phases[rsIdx].Type)
+			}
+		}
+	}
+	return nil
+}
+
+// removeExternalDependencies removes the dependencies on the completed
+// plugins (i.e., plugins run in the earlier phases), on the plugins of the
+// later phases, as well as the plugin references from the normalized plugins
+// info, and returns the dependencies blocking each plugin.
+//
+//	NOTE: The completed or referenced plugins that didn't succeed block the
+//	plugins requiring them (see isDependencyMet()). The plugins of the later
+//	phases appear as dependencies only when they're RequiredBy the current
+//	plugins, and they're run later anyway.
+func removeExternalDependencies(nPInfo Plugins, completedPlugins Plugins,
+	laterPlugins []string) map[string][]string {
+	blockedBy := map[string][]string{}
+	resolver := newPluginRefResolver(completedPlugins)
+	for pIdx, pInfo := range nPInfo {
+		// INFO: Consider the completed plugins that are RequiredBy the plugin
+		// 	as its Requires, as the completed plugins aren't normalized along
+		// 	with the current plugins.
+		requires := append([]string{}, pInfo.Requires...)
+		for _, cInfo := range completedPlugins {
+			if containsString(cInfo.RequiredBy, pInfo.Name) && !containsString(requires, cInfo.Name) {
+				requires = append(requires, cInfo.Name)
+			}
+		}
+		nPInfo[pIdx].Requires = []string{}
+		for _, rs := range requires {
+			if containsString(laterPlugins, rs) {
+				continue
+			}
+			var cInfo Plugin
+			var ok bool
+			if isPluginRef(rs) {
+				cInfo, ok = resolver.resolve(rs)
+				if !ok {
+					logger.Info.Printf("Plugin(%s): Referenced plugin %s didn't run.", pInfo.Name, rs)
+					blockedBy[pInfo.Name] = append(blockedBy[pInfo.Name], rs)
+					continue
+				}
+			} else if cInfo, ok = resolver.completed[rs]; !ok {
+				nPInfo[pIdx].Requires = append(nPInfo[pIdx].Requires, rs)
+				continue
+			}
+			if !isDependencyMet(cInfo, pInfo, isPluginRef(rs)) {
+				blockedBy[pInfo.Name] = append(blockedBy[pInfo.Name], rs)
+			}
+		}
+	}
+	return blockedBy
+}
+
+// runPhases runs the plugin types of the phases in the order of the phases.
+// The phases after a failed phase are skipped.
+func runPhases(result *RunStatus, runOptions RunOptions) error {
+	logger.Debug.Printf("Entering runPhases(%+v, %+v)...", result, runOptions)
+	defer logger.Debug.Println("Exiting runPhases")
+
+	if err := validatePhaseDependencies(result.Phases); err != nil {
+		result.Status = dStatusFail
+		result.StdOutErr = err.Error()
+		return err
+	}
+
+	completedPlugins := Plugins{}
+	failedPhase := ""
+	for phaseIdx := range result.Phases {
+		phase := &result.Phases[phaseIdx]
+		if phase.Library == "" {
+			phase.Library = result.Library
+		}
+		if failedPhase != "" {
+			reason := "skipped as " + failedPhase + " phase failed"
+			logger.ConsoleInfo.Printf("Skipping %s plugins as %s phase failed.", phase.Type, failedPhase)
+			initGraph(phase.Type, phase.Plugins)
+			for pIdx := range phase.Plugins {
+				phase.Plugins[pIdx].Status = dStatusSkip
+				phase.Plugins[pIdx].Reason = reason
+				updateGraph(phase.Type, phase.Plugins[pIdx].Name, dStatusSkip, "")
+			}
+			phase.Status = dStatusSkip
+			continue
+		}
+		phaseOptions := runOptions
+		phaseOptions.Type = phase.Type
+		phaseOptions.completedPlugins = completedPlugins
+		phaseOptions.laterPlugins = []string{}
+		for _, laterPhase := range result.Phases[phaseIdx+1:] {
+			for _, pInfo := range laterPhase.Plugins {
+				phaseOptions.laterPlugins = append(phaseOptions.laterPlugins, pInfo.Name)
+			}
+		}
+		if err := run(phase, phaseOptions); err != nil {
+			failedPhase = phase.Type
+		}
+		completedPlugins = append(completedPlugins, phase.Plugins...)
+	}
+
+	if failedPhase != "" {
+		result.Status = dStatusFail
+		err := fmt.Errorf("Running %s plugins: %s", result.Type, dStatusFail)
+		result.StdOutErr = err.Error()
+		logger.ConsoleError.Printf("%s\n", err.Error())
+		return err
+	}
+	result.Status = dStatusOk
+	logger.ConsoleInfo.Printf("Running %s plugins: %s\n", result.Type, dStatusOk)
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func Test_readPhasesFile(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "YAML phases file",
+			contents: "phases:\n  - preupgrade\n  - prereboot\n",
+			want:     []string{"preupgrade", "prereboot"},
+		},
+		{
+			name:     "JSON phases file",
+			contents: `{"phases": ["preupgrade", "postreboot"]}`,
+			want:     []string{"preupgrade", "postreboot"},
+		},
+		{
+			name:     "No phases",
+			contents: "phases: []\n",
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid format",
+			contents: "phases: preupgrade\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phasesFile := filepath.Join(t.TempDir(), "phases.yaml")
+			if err := os.WriteFile(phasesFile, []byte(tt.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readPhasesFile(phasesFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readPhasesFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readPhasesFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFromLibrary_phases(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	type phaseStatus struct {
+		Type    string
+		Status  string
+		Plugins map[string]string
+	}
+	tests := []struct {
+		name       string
+		files      map[string]string
+		pluginType string
+		wantStatus string
+		wantPhases []phaseStatus
+		wantReason map[string]string
+	}{
+		{
+			name: "Cross-phase dependencies are met by the earlier phases",
+			files: map[string]string{
+				"A/a.pre":  "Description=A\nExecStart=/bin/echo A\nRequiredBy=C/c.post\n",
+				"B/b.post": "Description=B\nExecStart=/bin/echo B\nRequires=A/a.pre\n",
+				"C/c.post": "Description=C\nExecStart=/bin/echo C\n",
+			},
+			pluginType: "pre,post",
+			wantStatus: dStatusOk,
+			wantPhases: []phaseStatus{
+				{Type: "pre", Status: dStatusOk, Plugins: map[string]string{"A/a.pre": dStatusOk}},
+				{Type: "post", Status: dStatusOk,
+					Plugins: map[string]string{"B/b.post": dStatusOk, "C/c.post": dStatusOk}},
+			},
+		},
+		{
+			name: "Ignored failure of an earlier phase skips the opted-in dependents",
+			files: map[string]string{
+				"A/a.pre":  "Description=A\nExecStart=/bin/false\nFailureMode=ignore\n",
+				"B/b.post": "Description=B\nExecStart=/bin/echo B\nRequires=A/a.pre\nSkipOnIgnoredFailure=yes\n",
+				"C/c.post": "Description=C\nExecStart=/bin/echo C\nRequires=A/a.pre B/b.post\n",
+			},
+			pluginType: "pre, post",
+			wantStatus: dStatusOk,
+			wantPhases: []phaseStatus{
+				{Type: "pre", Status: dStatusOk, Plugins: map[string]string{"A/a.pre": dStatusFailIgnored}},
+				{Type: "post", Status: dStatusOk,
+					Plugins: map[string]string{"B/b.post": dStatusSkip, "C/c.post": dStatusSkip}},
+			},
+			wantReason: map[string]string{
+				"B/b.post": "dependencies not met: A/a.pre; root cause: A/a.pre",
+				"C/c.post": "dependencies not met: B/b.post; root cause: A/a.pre",
+			},
+		},
+		{
+			name: "Phases after a failed phase are skipped",
+			files: map[string]string{
+				"A/a.pre":  "Description=A\nExecStart=/bin/false\n",
+				"B/b.post": "Description=B\nExecStart=/bin/echo B\n",
+			},
+			pluginType: "pre,post",
+			wantStatus: dStatusFail,
+			wantPhases: []phaseStatus{
+				{Type: "pre", Status: dStatusFail, Plugins: map[string]string{"A/a.pre": dStatusFail}},
+				{Type: "post", Status: dStatusSkip, Plugins: map[string]string{"B/b.post": dStatusSkip}},
+			},
+			wantReason: map[string]string{"B/b.post": "skipped as pre phase failed"},
+		},
+		{
+			name: "Plugin requiring a plugin of a later phase",
+			files: map[string]string{
+				"A/a.pre":  "Description=A\nExecStart=/bin/echo A\nRequires=B/b.post\n",
+				"B/b.post": "Description=B\nExecStart=/bin/echo B\n",
+			},
+			pluginType: "pre,post",
+			wantStatus: dStatusFail,
+			wantPhases: []phaseStatus{
+				{Type: "pre", Plugins: map[string]string{"A/a.pre": ""}},
+				{Type: "post", Plugins: map[string]string{"B/b.post": ""}},
+			},
+		},
+	}
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := createTestLibrary(t, tt.files)
+			result := RunStatus{}
+			err := RunFromLibrary(&result, tt.pluginType, RunOptions{Library: library})
+			if (err != nil) != (tt.wantStatus == dStatusFail) {
+				t.Errorf("RunFromLibrary() error = %v, want status %s", err, tt.wantStatus)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("RunFromLibrary() Status = %s, want %s", result.Status, tt.wantStatus)
+			}
+			if result.Type != "pre,post" || len(result.Plugins) != 0 {
+				t.Errorf("RunFromLibrary() Type = %s, Plugins = %+v, want phases only",
+					result.Type, result.Plugins)
+			}
+			gotPhases := []phaseStatus{}
+			for _, phase := range result.Phases {
+				ps := phaseStatus{Type: phase.Type, Status: phase.Status, Plugins: map[string]string{}}
+				for _, pInfo := range phase.Plugins {
+					ps.Plugins[pInfo.Name] = pInfo.Status
+					if want, ok := tt.wantReason[pInfo.Name]; ok && pInfo.Reason != want {
+						t.Errorf("Plugin %s Reason = %s, want %s", pInfo.Name, pInfo.Reason, want)
+					}
+				}
+				gotPhases = append(gotPhases, ps)
+			}
+			if !reflect.DeepEqual(gotPhases, tt.wantPhases) {
+				t.Errorf("RunFromLibrary() Phases = %+v, want %+v", gotPhases, tt.wantPhases)
+			}
+		})
+	}
+}
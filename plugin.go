@@ -6,6 +6,7 @@ package pm
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -18,6 +19,8 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,12 +38,39 @@ var (
 
 // Status of plugin execution used for displaying to user on console.
 const (
-	dStatusFail  = "Failed"
-	dStatusOk    = "Succeeded"
-	dStatusSkip  = "Skipped"
-	dStatusStart = "Starting"
+	dStatusFail        = "Failed"
+	dStatusFailIgnored = "FailedIgnored"
+	dStatusOk          = "Succeeded"
+	dStatusSkip        = "Skipped"
+	dStatusStart       = "Starting"
 )
 
+// Failure modes of a plugin i.e., how a plugin failure affects the run.
+//
+//	When failure mode is not specified, a plugin failure fails the run, and
+//	its dependents are skipped, while the other plugins continue to run.
+const (
+	// failureModeIgnore marks the failure as ignored, and doesn't fail the run.
+	failureModeIgnore = "ignore"
+	// failureModeWarn is same as failureModeIgnore, but also warns on console.
+	failureModeWarn = "warn"
+	// failureModeFatal fails the run, and stops scheduling remaining plugins.
+	failureModeFatal = "fatal"
+)
+
+// dReasonTerminated is the reason of the plugins terminated as the run is
+// aborted.
+const dReasonTerminated = "terminated"
+
+// dReasonTimedOut is the reason of the plugins terminated as they didn't
+// complete within their TimeoutSec.
+const dReasonTimedOut = "timed out"
+
+// pluginWaitDelay is the time to wait for the plugin output to be closed after
+// the plugin is terminated, as the processes that left the plugin process
+// group could keep it open.
+const pluginWaitDelay = 5 * time.Second
+
 // Plugin is plugin's info: name, description, cmd to run, status, stdouterr.
 type Plugin struct {
 	Name        string
@@ -48,8 +78,52 @@ type Plugin struct {
 	ExecStart   string
 	RequiredBy  []string
 	Requires    []string
-	Status      string
-	StdOutErr   []string
+	// FailureMode is one of "ignore", "warn" or "fatal".
+	FailureMode string `yaml:",omitempty" json:",omitempty"`
+	// SkipOnIgnoredFailure skips the plugin even when its dependency failure
+	// 	is ignored (i.e., dependency has "ignore" or "warn" FailureMode).
+	SkipOnIgnoredFailure bool `yaml:",omitempty" json:",omitempty"`
+	// TimeoutSec is the time in seconds within which the plugin must
+	// 	complete, after which it's terminated and marked as failed.
+	TimeoutSec int `yaml:",omitempty" json:",omitempty"`
+	// Disabled plugins are treated as succeeded without being run, similar
+	// 	to the plugins disabled using the "pm disable" command.
+	Disabled bool `yaml:",omitempty" json:",omitempty"`
+	// Tags are the labels (Ex: "storage", "quick") used to select a subset
+	// 	of plugins of a type using "-tags" and "-exclude-tags" options.
+	Tags []string `yaml:",omitempty" json:",omitempty"`
+	// Instances, and the base names of the paths matching InstancesGlob, as
+	// 	well as the output lines of the InstancesFrom command are the
+	// 	instances of a template plugin (i.e., "<component>/<name>@.<type>").
+	Instances     []string `yaml:",omitempty" json:",omitempty"`
+	InstancesGlob string   `yaml:",omitempty" json:",omitempty"`
+	InstancesFrom string   `yaml:",omitempty" json:",omitempty"`
+	// Library is the plugins library from which the plugin was read. It's
+	// 	exposed to the plugin as PM_LIBRARY env value.
+	Library string `yaml:",omitempty" json:",omitempty"`
+	// Generator is the generator (i.e., "<component>/<name>.<type>-gen")
+	// 	that generated the plugin.
+	Generator string `yaml:",omitempty" json:",omitempty"`
+	// State is either "disabled" or "masked" when the plugin is disabled or
+	// 	masked using the "pm disable" or "pm mask" commands.
+	State  string `yaml:",omitempty" json:",omitempty"`
+	Status string
+	// Reason informs why the plugin has the current status.
+	Reason string `yaml:",omitempty" json:",omitempty"`
+	// BlockedBy lists the required plugins that didn't succeed, due to which
+	// 	the plugin is skipped.
+	BlockedBy []string `yaml:",omitempty" json:",omitempty"`
+	// Outputs are the "<key>=<value>" outputs written by the plugin to the
+	// 	PM_OUTPUT file, which are passed to the plugins requiring it.
+	Outputs map[string]string `yaml:",omitempty" json:",omitempty"`
+	// Attempt is the number of times the plugin was run, including the runs
+	// 	whose results are passed as the plugins info to rerun the plugins.
+	Attempt int `yaml:",omitempty" json:",omitempty"`
+	// StartTime and EndTime are the times when the plugin command was
+	// 	started and when it completed.
+	StartTime time.Time `yaml:",omitempty" json:",omitempty"`
+	EndTime   time.Time `yaml:",omitempty" json:",omitempty"`
+	StdOutErr []string
 }
 
 // Plugins is a list of plugins' info.
@@ -57,16 +131,25 @@ type Plugins []Plugin
 
 // RunStatus is the pm run status.
 type RunStatus struct {
+	// RunID identifies the run. It's exposed to the plugins as PM_RUN_ID env
+	// 	value.
+	RunID   string `yaml:",omitempty" json:",omitempty"`
 	Type    string
 	Library string
+	// DryRun indicates that the plugins were run in dry run mode.
+	DryRun bool `yaml:",omitempty" json:",omitempty"`
 	// TODO: Add Percentage to get no. of pending vs. completed run of plugins.
-	Plugins   Plugins `yaml:",omitempty"`
+	Plugins Plugins `yaml:",omitempty"`
+	// Phases are the run status of each plugin type, when multiple plugin
+	// 	types (i.e., comma separated Type) are run as ordered phases.
+	Phases    []RunStatus `yaml:",omitempty" json:",omitempty"`
 	Status    string
 	StdOutErr string
 }
 
 // getPluginFiles retrieves the plugin files under each component matching
-// the specified pluginType.
+// the specified pluginType. The plugin files include both the plugin unit
+// files and the plugin definition files (i.e., "<name>.<type>.{yaml|yml|json}").
 func getPluginFiles(pluginType, library string) ([]string, error) {
 	logger.Debug.Println("Entering getPluginFiles")
 	defer logger.Debug.Println("Exiting getPluginFiles")
@@ -76,45 +159,104 @@ func getPluginFiles(pluginType, library string) ([]string, error) {
 		return pluginFiles, logger.ConsoleError.PrintNReturnError("Library '%s' doesn't exist. "+
 			"A valid plugins library path must be specified.", library)
 	}
-	var files []string
-	dirs, err := ioutil.ReadDir(library)
+	files, err := getLibraryFiles(library, config.GetLibraryDepth())
 	if err != nil {
-		logger.Error.Printf("Failed to call ioutil.ReadDir(%s), err=%s", library, err.Error())
-		return pluginFiles, logger.ConsoleError.PrintNReturnError("Failed to get contents of %s plugins library.", library)
+		return pluginFiles, err
 	}
 
-	for _, dir := range dirs {
-		compPluginDir := filepath.FromSlash(library + "/" + dir.Name())
-		fi, err := os.Stat(compPluginDir)
+	for _, file := range files {
+		matched, err := regexp.MatchString("[.]"+pluginType+"$", getPluginName(file))
 		if err != nil {
-			logger.Error.Printf("Unable to stat on %s directory, err=%s", dir, err.Error())
+			logger.Error.Printf("Failed to call regexp.MatchString(%s, %s), err=%s", "[.]"+pluginType, getPluginName(file), err.Error())
 			continue
 		}
-		if !fi.IsDir() {
-			logger.Error.Printf("%s is not a directory.", compPluginDir)
-			continue
+		if matched == true {
+			pluginFiles = append(pluginFiles, file)
 		}
+	}
+	return pluginFiles, nil
+}
 
-		tfiles, err := ioutil.ReadDir(compPluginDir)
+// getLibraryFiles returns the files present in the component directories of
+// the library, relative to the library. The component directories could be
+// nested up to maxDepth levels i.e., with maxDepth as 2, both
+// "<component>/<file>" and "<product>/<component>/<file>" files are returned.
+//
+//	NOTE: Symlinked directories are followed, but a directory that is
+//	already being read (i.e., a symlink loop) is skipped. The drop-in
+//	directories (i.e., "<plugin>.d" next to the plugin file) and the files
+//	directly under the library are skipped, while other directories ending
+//	with ".d" (Ex: "init.d") are read as component directories.
+func getLibraryFiles(library string, maxDepth int) ([]string, error) {
+	var files []string
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+	// ancestors tracks the real paths of the directories being read to
+	// 	detect symlink loops.
+	ancestors := map[string]bool{}
+	var readDir func(relDir string, depth int) error
+	readDir = func(relDir string, depth int) error {
+		dir := filepath.FromSlash(library + "/" + relDir)
+		realDir, err := filepath.EvalSymlinks(dir)
 		if err != nil {
-			logger.Error.Printf("Unable to read contents of %s directory, err=%s", compPluginDir, err.Error())
+			logger.Error.Printf("Unable to resolve %s directory, err=%s", dir, err.Error())
+			return nil
 		}
-		for _, tf := range tfiles {
-			files = append(files, filepath.FromSlash(dir.Name()+"/"+tf.Name()))
+		if ancestors[realDir] {
+			logger.Warning.Printf("Skipping %s directory as it's a symlink loop.", dir)
+			return nil
 		}
-	}
+		ancestors[realDir] = true
+		defer delete(ancestors, realDir)
 
-	for _, file := range files {
-		matched, err := regexp.MatchString("[.]"+pluginType+"$", file)
+		entries, err := ioutil.ReadDir(dir)
 		if err != nil {
-			logger.Error.Printf("Failed to call regexp.MatchString(%s, %s), err=%s", "[.]"+pluginType, file, err.Error())
-			continue
+			if depth == 0 {
+				logger.Error.Printf("Failed to call ioutil.ReadDir(%s), err=%s", library, err.Error())
+				return logger.ConsoleError.PrintNReturnError("Failed to get contents of %s plugins library.", library)
+			}
+			logger.Error.Printf("Unable to read contents of %s directory, err=%s", dir, err.Error())
+			return nil
 		}
-		if matched == true {
-			pluginFiles = append(pluginFiles, file)
+		// pluginNames are the names of the plugins defined by the files in
+		// 	the directory, to identify their drop-in directories.
+		pluginNames := map[string]bool{}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				pluginNames[getPluginName(entry.Name())] = true
+			}
 		}
+		for _, entry := range entries {
+			relPath := filepath.FromSlash(path.Join(relDir, entry.Name()))
+			fi, err := os.Stat(filepath.FromSlash(library + "/" + relPath))
+			if err != nil {
+				logger.Error.Printf("Unable to stat on %s, err=%s", relPath, err.Error())
+				continue
+			}
+			if !fi.IsDir() {
+				if depth == 0 {
+					logger.Debug.Printf("Skipping %s as it's not a directory.", relPath)
+					continue
+				}
+				files = append(files, relPath)
+				continue
+			}
+			if depth == maxDepth {
+				continue
+			}
+			if strings.HasSuffix(entry.Name(), ".d") &&
+				pluginNames[strings.TrimSuffix(entry.Name(), ".d")] {
+				continue
+			}
+			if err := readDir(relPath, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	return pluginFiles, nil
+	err := readDir("", 0)
+	return files, err
 }
 
 // getPluginType returns the plugin type of the specified plugin file.
@@ -184,22 +326,89 @@ func getPluginsInfoFromLibrary(pluginType, library string) (Plugins, error) {
 	if err != nil {
 		return pluginsInfo, err
 	}
+	state, err := loadPluginsState()
+	if err != nil {
+		return pluginsInfo, err
+	}
+	// pluginFileNames tracks the file defining each plugin to detect the
+	// 	plugins defined in multiple formats.
+	pluginFileNames := map[string]string{}
 	for file := range pluginFiles {
-		fContents, rerr := readFile(filepath.FromSlash(
-			library + pluginFiles[file]))
-		if rerr != nil {
-			return pluginsInfo, logger.ConsoleError.PrintNReturnError(rerr.Error())
+		pName := getPluginName(pluginFiles[file])
+		if prevFile, ok := pluginFileNames[pName]; ok {
+			return pluginsInfo, logger.ConsoleError.PrintNReturnError(
+				"Plugin %s is defined in both %s and %s.", pName, prevFile, pluginFiles[file])
 		}
-		logger.Debug.Printf("Plugin file %s contents: \n%s\n",
-			pluginFiles[file], fContents)
-		pInfo, perr := parseUnitFile(fContents)
+		pluginFileNames[pName] = pluginFiles[file]
+		up := unitParser{}
+		pInfo, _, perr := loadPlugin(library, config.GetPluginsOverrideLibrary(),
+			pluginFiles[file], &up)
 		if perr != nil {
 			return pluginsInfo, perr
 		}
 		logger.Info.Printf("Plugin %s info: %+v", pluginFiles[file], pInfo)
-		pInfo.Name = pluginFiles[file]
 		pluginsInfo = append(pluginsInfo, pInfo)
 	}
+	generatedPlugins, err := runGenerators(pluginType, library)
+	if err != nil {
+		return pluginsInfo, err
+	}
+	for _, pInfo := range generatedPlugins {
+		if prevFile, ok := pluginFileNames[pInfo.Name]; ok {
+			return pluginsInfo, logger.ConsoleError.PrintNReturnError(
+				"Plugin %s is defined in both %s and %s.", pInfo.Name, prevFile, pInfo.Generator)
+		}
+		pluginFileNames[pInfo.Name] = pInfo.Generator
+		pluginsInfo = append(pluginsInfo, pInfo)
+	}
+	pluginsInfo, err = expandPluginTemplates(pluginsInfo, library)
+	if err != nil {
+		return pluginsInfo, err
+	}
+	state.applyTo(pluginsInfo)
+	return pluginsInfo, nil
+}
+
+// getPluginsInfoFromLibraries retrieves the plugins of specified type from
+// the libraries. The plugins in the libraries listed first override the
+// same-named plugins in the libraries listed later.
+//
+//	NOTE: When multiple libraries are specified, the libraries that don't
+//	exist are skipped.
+func getPluginsInfoFromLibraries(pluginType string, libraries []string) (Plugins, error) {
+	logger.Debug.Printf("Entering getPluginsInfoFromLibraries(%s, %v)...", pluginType, libraries)
+	defer logger.Debug.Println("Exiting getPluginsInfoFromLibraries")
+
+	var pluginsInfo Plugins
+	// pluginLibraries tracks the library from which each plugin was read.
+	pluginLibraries := map[string]string{}
+	for _, library := range libraries {
+		if _, err := os.Stat(library); os.IsNotExist(err) && len(libraries) > 1 {
+			logger.Warning.Printf("Skipping library %s as it doesn't exist.", library)
+			continue
+		}
+		libPluginsInfo, err := getPluginsInfoFromLibrary(pluginType, library)
+		if err != nil {
+			return pluginsInfo, err
+		}
+		for _, pInfo := range libPluginsInfo {
+			if prevLibrary, ok := pluginLibraries[pInfo.Name]; ok {
+				logger.Info.Printf("Plugin %s of %s library is overridden by %s library.",
+					pInfo.Name, library, prevLibrary)
+				continue
+			}
+			pluginLibraries[pInfo.Name] = library
+			pInfo.Library = library
+			pluginsInfo = append(pluginsInfo, pInfo)
+		}
+	}
+	if len(pluginLibraries) == 0 && len(libraries) > 1 {
+		return pluginsInfo, logger.ConsoleError.PrintNReturnError(
+			"None of the libraries '%s' have %s plugins.", strings.Join(libraries, "', '"), pluginType)
+	}
+	sort.SliceStable(pluginsInfo, func(i, j int) bool {
+		return pluginsInfo[i].Name < pluginsInfo[j].Name
+	})
 	return pluginsInfo, nil
 }
 
@@ -211,13 +420,11 @@ func normalizePluginsInfo(pluginsInfo Plugins) Plugins {
 	pluginIndexes := make(map[string]int, len(pluginsInfo))
 	for pIdx, pInfo := range pluginsInfo {
 		pluginIndexes[pInfo.Name] = pIdx
-		nPInfo[pIdx] = Plugin{
-			Name:        pInfo.Name,
-			Description: pInfo.Description,
-			ExecStart:   pInfo.ExecStart,
-		}
-		nPInfo[pIdx].RequiredBy = append(nPInfo[pIdx].Requires, pInfo.RequiredBy...)
-		nPInfo[pIdx].Requires = append(nPInfo[pIdx].Requires, pInfo.Requires...)
+		// INFO: Copy all plugin attributes, but make a copy of dependencies
+		// 	as they get updated below.
+		nPInfo[pIdx] = pInfo
+		nPInfo[pIdx].RequiredBy = append([]string(nil), pInfo.RequiredBy...)
+		nPInfo[pIdx].Requires = append([]string(nil), pInfo.Requires...)
 		logger.Debug.Printf("%s plugin dependencies: %v", nPInfo[pIdx].Name, nPInfo[pIdx])
 	}
 	for pIdx, pInfo := range nPInfo {
@@ -253,7 +460,10 @@ func normalizePluginsInfo(pluginsInfo Plugins) Plugins {
 			logger.Debug.Printf("nPInfo of %s: %+v", rby, nPInfo[rbyIdx])
 			// INFO: If one plugin type is added as dependent on another by
 			// any chance, then skip checking its contents as the other
-			// plugin type files were not parsed.
+			// plugin type files were not parsed. The dependencies on other
+			// plugin types are expected to be specified as plugin references
+			// (i.e., "Requires=@<type>:<plugin>"), which are checked while
+			// running the plugins.
 			if _, ok := pluginIndexes[rby]; !ok {
 				// NOTE: Add the missing plugin in Requires, So that the issue
 				// gets caught during validation.
@@ -277,57 +487,6 @@ func normalizePluginsInfo(pluginsInfo Plugins) Plugins {
 	return nPInfo
 }
 
-// parseUnitFile parses the plugin file contents.
-func parseUnitFile(fileContents string) (Plugin, error) {
-	logger.Debug.Println("Entering parseUnitFile")
-	defer logger.Debug.Println("Exiting parseUnitFile")
-
-	pluginInfo := Plugin{}
-	if len(fileContents) == 0 {
-		return pluginInfo, nil
-	}
-	lines := strings.Split(fileContents, "\n")
-	for l := range lines {
-		line := strings.TrimSpace(lines[l])
-		logger.Debug.Println("line...", line)
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
-			continue
-		}
-		if strings.HasPrefix(line, "#") {
-			// No need to parse comments.
-			logger.Debug.Println("Skipping comment line...", line)
-			continue
-		}
-
-		fields := strings.Split(line, "=")
-		if len(fields) == 0 {
-			continue
-		}
-		key := strings.TrimSpace(fields[0])
-		val := strings.TrimSpace(strings.Join(fields[1:], "="))
-		switch key {
-		case "Description":
-			pluginInfo.Description = val
-			break
-		case "ExecStart":
-			pluginInfo.ExecStart = val
-			break
-		case "RequiredBy":
-			pluginInfo.RequiredBy = strings.Split(val, " ")
-			break
-		case "Requires":
-			pluginInfo.Requires = strings.Split(val, " ")
-			break
-		default:
-			logger.Debug.Printf("Non-standard line found: %s", line)
-			break
-		}
-	}
-
-	return pluginInfo, nil
-}
-
 func validateDependencies(nPInfo Plugins) ([]string, error) {
 	logger.Debug.Println("Entering validateDependencies")
 	defer logger.Debug.Println("Exiting validateDependencies")
@@ -345,6 +504,10 @@ func validateDependencies(nPInfo Plugins) ([]string, error) {
 		pName := nPInfo[pNameIndex].Name
 		pContents := nPInfo[pNameIndex]
 		logger.Debug.Printf("\nPlugin: %s \n%+v \n\n", pName, pContents)
+		// NOTE: The plugin references are checked while running the plugins
+		// 	(see removeExternalDependencies()), and don't affect the order.
+		pContents.Requires = removePluginRefs(pContents.Requires)
+		nPInfo[pNameIndex].Requires = pContents.Requires
 		if len(pContents.Requires) == 0 {
 			dependencyMet[pName] = true
 			pluginOrder = append(pluginOrder, pName)
@@ -372,7 +535,9 @@ func validateDependencies(nPInfo Plugins) ([]string, error) {
 
 		dependencyMet[pName] = true
 		for w := range pDependencies {
-			val := dependencyMet[pDependencies[w]]
+			// NOTE: A plugin requiring itself is a circular dependency, and
+			// 	its own dependencyMet value is set above, so check it explicitly.
+			val := dependencyMet[pDependencies[w]] && pDependencies[w] != pName
 			if false == val {
 				// If dependency met is false, then process it later again after all dependencies are met.
 				dependencyMet[pName] = false
@@ -397,10 +562,10 @@ func validateDependencies(nPInfo Plugins) ([]string, error) {
 				// INFO: Clear out the pluginOrder as we cannot run all the
 				// 	plugins either due to missing dependencies or having
 				// 	circular dependency.
-				return []string{}, logger.ConsoleError.PrintNReturnError(
-					"There is either a circular dependency between plugins, "+
-						"or some dependencies are missing in these plugins: %+v",
-					notPlacedPlugins)
+				logger.Error.Printf("Unable to place these plugins: %+v", notPlacedPlugins)
+				depErr := diagnoseDependencies(nPInfo, notPlacedPlugins)
+				logger.ConsoleError.Printf("%s", depErr.Error())
+				return []string{}, depErr
 			}
 			prevLen = curLen
 			elementsLeft = curLen
@@ -410,7 +575,7 @@ func validateDependencies(nPInfo Plugins) ([]string, error) {
 	return pluginOrder, nil
 }
 
-func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDependency bool, env map[string]string) {
+func executePluginCmd(ctx context.Context, statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDependency bool, env map[string]string, inputs map[string]map[string]string) {
 	p := pInfo.Name
 	logger.Debug.Printf("Channel: Plugin %s info: \n%+v", p, pInfo)
 	updateGraph(getPluginType(p), p, dStatusStart, "")
@@ -452,9 +617,18 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 	// then just return that status.
 	myStatus := ""
 	myStatusMsg := ""
-	if failedDependency {
+	myReason := ""
+	if pInfo.State == dStateMasked {
+		myStatusMsg = "Skipping as plugin is masked."
+		myStatus = dStatusSkip
+		myReason = dStateMasked
+	} else if failedDependency {
 		myStatusMsg = "Skipping as its dependency failed."
 		myStatus = dStatusSkip
+	} else if pInfo.State == dStateDisabled || pInfo.Disabled {
+		myStatusMsg = "Passing as plugin is disabled."
+		myStatus = dStatusOk
+		myReason = dStateDisabled
 	} else if pInfo.ExecStart == "" {
 		myStatusMsg = "Passing as ExecStart value is empty!"
 		myStatus = dStatusOk
@@ -465,9 +639,10 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 		logger.Info.Printf("Plugin(%s): %s", p, myStatusMsg)
 		updateGraph(getPluginType(p), p, myStatus, "")
 		logger.ConsoleInfo.Printf("%s: %s", pInfo.Description, myStatus)
-		statusCh <- map[string]*Plugin{p: {Status: myStatus}}
+		statusCh <- map[string]*Plugin{p: {Status: myStatus, Reason: myReason, Attempt: pInfo.Attempt}}
 		return
 	}
+	attempt := pInfo.Attempt + 1
 
 	// INFO: First initialize with existing OS env, and then overwrite any
 	// 	existing keys with user specified values. I.e., Even if PM_LIBRARY
@@ -479,6 +654,62 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 		envList = append(envList, envKey+"="+envValue)
 		envMap[envKey] = envValue
 	}
+	// INFO: When plugins are read from multiple libraries, PM_LIBRARY is the
+	// 	library from which the plugin was read.
+	if pInfo.Library != "" {
+		envList = append(envList, "PM_LIBRARY="+pInfo.Library)
+		envMap["PM_LIBRARY"] = pInfo.Library
+	}
+	pluginLogPath := ""
+	if pluginLogFile != "" {
+		pluginLogPath = config.GetPMLogDir() + pluginLogFile
+	}
+	// INFO: The plugin specific env values, so that the plugins could write
+	// 	consistent logs and state without hardcoding the paths.
+	for envKey, envValue := range map[string]string{
+		"PM_PLUGIN_NAME":     p,
+		"PM_PLUGIN_TYPE":     getPluginType(p),
+		"PM_PLUGIN_LOG_FILE": pluginLogPath,
+		"PM_ATTEMPT":         strconv.Itoa(attempt),
+		"PM_STATE_DIR":       getPluginStateDir(p),
+	} {
+		envList = append(envList, envKey+"="+envValue)
+		envMap[envKey] = envValue
+	}
+	if err := osutils.OsMkdirAll(envMap["PM_STATE_DIR"], 0755); err != nil {
+		logger.Error.Printf("Failed to create state dir %s of plugin %s, err=%s",
+			envMap["PM_STATE_DIR"], p, err.Error())
+		// Ignore error and continue as plugin state dir creation is not fatal.
+	}
+	// INFO: The plugin could write its outputs to the PM_OUTPUT file, while
+	// 	the outputs of its required plugins are passed as
+	// 	PM_IN_<plugin>_<key> env values, and in the PM_INPUTS json file.
+	ioDir, ioErr := createPluginIOFiles(inputs)
+	defer os.RemoveAll(ioDir)
+	if ioErr != nil {
+		logger.Error.Printf("Failed to create output and inputs files of plugin %s, err=%s",
+			p, ioErr.Error())
+		// Ignore error and continue as plugin outputs are optional.
+	} else {
+		envList = append(envList, outputEnv+"="+filepath.Join(ioDir, "output"),
+			inputsEnv+"="+filepath.Join(ioDir, "inputs.json"))
+		envMap[outputEnv] = filepath.Join(ioDir, "output")
+		envMap[inputsEnv] = filepath.Join(ioDir, "inputs.json")
+	}
+	inputEnvs, err := getInputsEnv(inputs)
+	if err != nil {
+		pInfo.Status = dStatusFail
+		logger.Error.Printf("Failed to execute plugin %s. Error: %s\n", pInfo.Name, err.Error())
+		pInfo.StdOutErr = []string{err.Error()}
+		pInfo.Attempt = attempt
+		logger.ConsoleInfo.Printf("%s: %s\n", pInfo.Description, pInfo.Status)
+		statusCh <- map[string]*Plugin{p: &pInfo}
+		return
+	}
+	for envKey, envValue := range inputEnvs {
+		envList = append(envList, envKey+"="+envValue)
+		envMap[envKey] = envValue
+	}
 
 	getEnvVal := func(name string) string {
 		// logger.Debug.Printf("In getEnvVal(%v)...", name)
@@ -498,13 +729,26 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 	cmdParam := strings.Split(os.Expand(pInfo.ExecStart, getEnvVal), " ")
 	cmdStr := cmdParam[0]
 	cmdParams := cmdParam[1:]
-	cmd := exec.Command(cmdStr, cmdParams...)
+	// INFO: The plugin gets terminated when the context gets cancelled, i.e.,
+	// 	when the run is aborted and running plugins are to be terminated.
+	// 	The plugin is also terminated when it doesn't complete within its
+	// 	TimeoutSec.
+	pluginCtx := ctx
+	if pInfo.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		pluginCtx, cancel = context.WithTimeout(ctx, time.Duration(pInfo.TimeoutSec)*time.Second)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(pluginCtx, cmdStr, cmdParams...)
+	setPluginProcessGroup(cmd)
+	cmd.WaitDelay = pluginWaitDelay
 	cmd.Env = envList
 	iostdout, err := cmd.StdoutPipe()
 	if err != nil {
 		pInfo.Status = dStatusFail
 		logger.Error.Printf("Failed to execute plugin %s. Error: %s\n", pInfo.Name, err.Error())
 		pInfo.StdOutErr = []string{err.Error()}
+		pInfo.Attempt = attempt
 		logger.ConsoleInfo.Printf("%s: %s\n", pInfo.Description, pInfo.Status)
 		statusCh <- map[string]*Plugin{p: &pInfo}
 		return
@@ -512,6 +756,7 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 	cmd.Stderr = cmd.Stdout
 
 	chLog.Println("Executing command:", pInfo.ExecStart)
+	startTime := time.Now()
 	err = cmd.Start()
 	var stdOutErr []string
 	if err == nil {
@@ -525,12 +770,14 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 		err = cmd.Wait()
 		// chLog.Printf("command exited with code: %+v", err)
 	}
+	endTime := time.Now()
 
+	failStatus := getFailureStatus(pInfo.FailureMode)
 	func() {
 		chLog.Printf("INFO: Plugin(%s): Executing command: %s", p, pInfo.ExecStart)
 		if err != nil {
 			chLog.Printf("ERROR: Plugin(%s): Failed to execute command, err=%s", p, err.Error())
-			updateGraph(getPluginType(p), p, dStatusFail, pluginLogFile)
+			updateGraph(getPluginType(p), p, failStatus, pluginLogFile)
 		} else {
 			chLog.Printf("INFO: Plugin(%s): Stdout & Stderr: %v", p, stdOutErr)
 			updateGraph(getPluginType(p), p, dStatusOk, pluginLogFile)
@@ -538,27 +785,77 @@ func executePluginCmd(statusCh chan<- map[string]*Plugin, pInfo Plugin, failedDe
 	}()
 
 	logger.Debug.Println("Stdout & Stderr:", stdOutErr)
-	pStatus := Plugin{StdOutErr: stdOutErr}
-	if err != nil {
+	pStatus := Plugin{StdOutErr: stdOutErr, Attempt: attempt,
+		StartTime: startTime, EndTime: endTime}
+	if ioErr == nil {
+		contents, rerr := readFile(filepath.Join(ioDir, "output"))
+		if rerr != nil {
+			logger.Error.Printf("Failed to read outputs of plugin %s, err=%s", p, rerr.Error())
+		}
+		outputs, invalidLines := parsePluginOutputs(contents)
+		for _, line := range invalidLines {
+			chLog.Printf("WARNING: Plugin(%s): Ignoring output '%s' as it's not in <key>=<value> format.",
+				p, line)
+		}
+		if len(outputs) != 0 {
+			chLog.Printf("INFO: Plugin(%s): Outputs: %v", p, outputs)
+			pStatus.Outputs = outputs
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		// NOTE: The run is aborted, so the plugin failure is not ignored.
 		pStatus.Status = dStatusFail
-		logger.Error.Printf("Failed to execute plugin %s. err=%s\n", p, err.Error())
+		pStatus.Reason = dReasonTerminated
+		logger.Error.Printf("Plugin %s terminated. err=%s\n", p, err.Error())
 		logger.ConsoleError.Printf("%s: %s\n", pInfo.Description, dStatusFail)
 		statusCh <- map[string]*Plugin{p: &pStatus}
 		return
 	}
+	if err != nil {
+		pStatus.Status = failStatus
+		pStatus.Reason = err.Error()
+		if pluginCtx.Err() == context.DeadlineExceeded {
+			pStatus.Reason = fmt.Sprintf("%s after %ds", dReasonTimedOut, pInfo.TimeoutSec)
+		}
+		logger.Error.Printf("Failed to execute plugin %s. err=%s\n", p, err.Error())
+		switch pInfo.FailureMode {
+		case failureModeIgnore:
+			logger.ConsoleInfo.Printf("%s: %s\n", pInfo.Description, failStatus)
+		case failureModeWarn:
+			logger.ConsoleWarning.Printf("%s: %s\n", pInfo.Description, failStatus)
+		default:
+			logger.ConsoleError.Printf("%s: %s\n", pInfo.Description, failStatus)
+		}
+		statusCh <- map[string]*Plugin{p: &pStatus}
+		return
+	}
 	pStatus.Status = dStatusOk
 	logger.ConsoleInfo.Printf("%s: %s\n", pInfo.Description, dStatusOk)
 	statusCh <- map[string]*Plugin{p: &pStatus}
 }
 
-func executePlugins(psStatus *Plugins, sequential bool, env map[string]string) bool {
-	logger.Debug.Printf("Entering executePlugins(%+v, %v, %+v)...",
-		psStatus, sequential, env)
+// getFailureStatus returns the status of a failed plugin based on its
+// failure mode.
+func getFailureStatus(failureMode string) string {
+	if failureMode == failureModeIgnore || failureMode == failureModeWarn {
+		return dStatusFailIgnored
+	}
+	return dStatusFail
+}
+
+func executePlugins(psStatus *Plugins, runOptions RunOptions, env map[string]string) bool {
+	logger.Debug.Printf("Entering executePlugins(%+v, %+v, %+v)...",
+		psStatus, runOptions, env)
 	defer logger.Debug.Println("Exiting executePlugins")
 
 	retStatus := true
+	sequential := runOptions.Sequential
 
 	nPInfo := normalizePluginsInfo(*psStatus)
+	upstreams := getPluginUpstreams(nPInfo, runOptions.completedPlugins)
+	resolver := newPluginRefResolver(runOptions.completedPlugins)
+	completedBlockedBy := removeExternalDependencies(nPInfo,
+		runOptions.completedPlugins, runOptions.laterPlugins)
 
 	_, err := validateDependencies(nPInfo)
 	if err != nil {
@@ -576,10 +873,52 @@ func executePlugins(psStatus *Plugins, sequential bool, env map[string]string) b
 	for pIdx, pInfo := range *psStatus {
 		pluginIndexes[pInfo.Name] = pIdx
 	}
+	nPluginIndexes := make(map[string]int, len(nPInfo))
+	for pIdx, pInfo := range nPInfo {
+		nPluginIndexes[pInfo.Name] = pIdx
+	}
 	executingCnt := 0
 	exeCh := make(chan map[string]*Plugin)
 	failedDependency := make(map[string]bool)
+	// blockedBy tracks the required plugins that didn't succeed, and
+	// 	rootCauses tracks the failed plugins due to which a plugin is skipped.
+	blockedBy := make(map[string][]string)
+	rootCauses := make(map[string][]string)
+	for p, rss := range completedBlockedBy {
+		failedDependency[p] = true
+		blockedBy[p] = rss
+		rootCauses[p] = rss
+	}
+	// abortedBy is the failed plugin due to which the run is aborted, i.e.,
+	// 	the first failed plugin when fail-fast is set, or a plugin with
+	// 	"fatal" failure mode.
+	abortedBy := ""
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	for len(pluginIndexes) > 0 || executingCnt != 0 {
+		if abortedBy != "" {
+			// INFO: Don't start any new plugins once the run is aborted,
+			// 	and mark all the plugins that are not yet started as skipped.
+			for _, pInfo := range nPInfo {
+				p := pInfo.Name
+				if waitCount[p] < 0 {
+					// Already executed or being executed.
+					continue
+				}
+				waitCount[p] = -1
+				reason := "aborted after failure of " + abortedBy
+				logger.Info.Printf("Plugin(%s): Skipping as %s.", p, reason)
+				updateGraph(getPluginType(p), p, dStatusSkip, "")
+				logger.ConsoleInfo.Printf("%s: %s", pInfo.Description, dStatusSkip)
+				(*psStatus)[pluginIndexes[p]].Status = dStatusSkip
+				(*psStatus)[pluginIndexes[p]].Reason = reason
+				(*psStatus)[pluginIndexes[p]].BlockedBy = nil
+				delete(pluginIndexes, p)
+			}
+			if executingCnt == 0 {
+				break
+			}
+		}
 		for _, pInfo := range nPInfo {
 			p := pInfo.Name
 			// INFO: When all dependencies are met, plugin waitCount would be 0.
@@ -592,7 +931,12 @@ func executePlugins(psStatus *Plugins, sequential bool, env map[string]string) b
 				logger.Info.Printf("Plugin %s is ready for execution: %v.", p, pInfo)
 				waitCount[p]--
 
-				go executePluginCmd(exeCh, pInfo, failedDependency[p], env)
+				pluginsStatus := map[string]Plugin{}
+				for _, ps := range *psStatus {
+					pluginsStatus[ps.Name] = ps
+				}
+				inputs := getPluginInputs(upstreams[p], pluginsStatus, resolver)
+				go executePluginCmd(ctx, exeCh, pInfo, failedDependency[p], env, inputs)
 				executingCnt++
 			}
 		}
@@ -605,18 +949,69 @@ func executePlugins(psStatus *Plugins, sequential bool, env map[string]string) b
 			ps := *psStatus
 			ps[pIdx].Status = pStatus.Status
 			ps[pIdx].StdOutErr = pStatus.StdOutErr
+			ps[pIdx].Outputs = pStatus.Outputs
+			ps[pIdx].Attempt = pStatus.Attempt
+			ps[pIdx].StartTime = pStatus.StartTime
+			ps[pIdx].EndTime = pStatus.EndTime
+			ps[pIdx].Reason = pStatus.Reason
+			// INFO: BlockedBy is reset, so that the BlockedBy of a plugin
+			// 	skipped in the previous run is not retained on rerun.
+			ps[pIdx].BlockedBy = nil
+			if pStatus.Reason == dReasonTerminated {
+				ps[pIdx].Reason = dReasonTerminated + " after failure of " + abortedBy
+			}
+			if pStatus.Status == dStatusSkip && failedDependency[plugin] &&
+				pStatus.Reason != dStateMasked {
+				ps[pIdx].BlockedBy = blockedBy[plugin]
+				ps[pIdx].Reason = fmt.Sprintf("dependencies not met: %s; root cause: %s",
+					strings.Join(blockedBy[plugin], ", "),
+					strings.Join(rootCauses[plugin], ", "))
+				logger.Info.Printf("Plugin(%s): Skipped as %s", plugin, ps[pIdx].Reason)
+			}
 			if pStatus.Status == dStatusFail {
 				retStatus = false
+				if abortedBy == "" && (runOptions.FailFast ||
+					nPInfo[pIdx].FailureMode == failureModeFatal) {
+					abortedBy = plugin
+					logger.ConsoleError.Printf("Aborting the run after failure of %s plugin.",
+						plugin)
+					if runOptions.TerminateOnAbort {
+						logger.Info.Println("Terminating the plugins being run.")
+						cancel()
+					}
+				}
 			}
 
+			// INFO: The root causes of a skipped plugin are passed on to its
+			// 	dependents, while a failed or masked plugin itself is the root
+			// 	cause.
+			causes := []string{plugin}
+			if pStatus.Status == dStatusSkip && pStatus.Reason != dStateMasked {
+				causes = rootCauses[plugin]
+			}
 			for _, rby := range nPInfo[pIdx].RequiredBy {
+				blocked := false
 				if pStatus.Status == dStatusFail ||
 					pStatus.Status == dStatusSkip {
 					// TODO: When "Wants" and "WantedBy" options are supported similar to
 					// 	"Requires" and "RequiredBy", the failedDependency flag should be
 					// 	checked in conjunction with if its required dependency is failed,
 					// 	and not the wanted dependency.
+					blocked = true
+				} else if pStatus.Status == dStatusFailIgnored &&
+					nPInfo[nPluginIndexes[rby]].SkipOnIgnoredFailure {
+					// INFO: Ignored failures don't affect dependents, unless
+					// 	dependents opt in to be skipped.
+					blocked = true
+				}
+				if blocked {
 					failedDependency[rby] = true
+					blockedBy[rby] = append(blockedBy[rby], plugin)
+					for _, cause := range causes {
+						if !containsString(rootCauses[rby], cause) {
+							rootCauses[rby] = append(rootCauses[rby], cause)
+						}
+					}
 				}
 				waitCount[rby]--
 			}
@@ -626,10 +1021,29 @@ func executePlugins(psStatus *Plugins, sequential bool, env map[string]string) b
 	return retStatus
 }
 
+// containsString checks whether the specified string is present in the list.
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
 // CmdOptions contains subcommands and parameters of the pm command.
 var CmdOptions struct {
-	RunCmd     *flag.FlagSet
-	ListCmd    *flag.FlagSet
+	RunCmd      *flag.FlagSet
+	ListCmd     *flag.FlagSet
+	ValidateCmd *flag.FlagSet
+	ShowCmd     *flag.FlagSet
+	HistoryCmd  *flag.FlagSet
+	ShowRunCmd  *flag.FlagSet
+	DiffRunsCmd *flag.FlagSet
+	ReportCmd   *flag.FlagSet
+	TimelineCmd *flag.FlagSet
+	// StateCmds are the disable, enable, mask and unmask subcommands.
+	StateCmds  map[string]*flag.FlagSet
 	versionCmd *flag.FlagSet
 	versionPtr *bool
 
@@ -637,6 +1051,38 @@ var CmdOptions struct {
 	// (If sequential is disabled, plugins whose dependencies are met would be executed in parallel).
 	sequential *bool
 
+	// failFast stops starting new plugins after the first plugin failure.
+	failFast *bool
+
+	// terminateOnAbort terminates the plugins being run when the run is
+	// aborted (i.e., due to failFast or a plugin with fatal failure mode).
+	terminateOnAbort *bool
+
+	// dryRun runs the plugins with PM_DRY_RUN env value set to true.
+	dryRun *bool
+
+	// historyTypePtr and historyLastPtr filter the runs displayed by the
+	// 	history command to the last runs of the plugin type.
+	historyTypePtr *string
+	historyLastPtr *int
+
+	// diffThresholdPtr and diffMinIncreasePtr are the percentage and the
+	// 	minimum increase in the duration of a plugin to be reported as a
+	// 	duration regression by the diff-runs command.
+	diffThresholdPtr   *float64
+	diffMinIncreasePtr *time.Duration
+
+	// reportParallelismPtr is the number of plugins that could be run at a
+	// 	time for estimating the wall time by the report command.
+	reportParallelismPtr *int
+
+	// timelineFilePtr is the file in which the timeline command generates
+	// 	the timeline image.
+	timelineFilePtr *string
+
+	// graphRenderer is the renderer used for generating the graph image.
+	graphRenderer string
+
 	// pluginsPtr specifies plugins Name and its Description, ExecStart and any dependencies (Requires, RequiredBy).
 	// For input format, check 'Plugins' struct.
 	pluginsPtr *string
@@ -644,37 +1090,100 @@ var CmdOptions struct {
 	// pluginTypePtr indicates type of the plugin to run.
 	pluginTypePtr *string
 
-	// libraryPtr indicates the path of the plugins library.
-	libraryPtr *string
+	// phasesFilePtr indicates the file listing the plugin types to be run
+	// 	as ordered phases.
+	phasesFilePtr *string
+
+	// libraries indicates the paths of the plugins libraries with the
+	// 	highest precedence library first.
+	libraries stringList
+
+	// only and exclude are the patterns of the plugins to be selected and
+	// 	excluded respectively.
+	only    stringList
+	exclude stringList
+
+	// tags and excludeTags are the tags of the plugins to be selected and
+	// 	excluded respectively.
+	tags        stringList
+	excludeTags stringList
+
+	// withDependencies and withDependents additionally select the plugins
+	// 	required by and requiring the selected plugins respectively.
+	withDependencies *bool
+	withDependents   *bool
 
 	// pluginDirPtr indicates the location of the plugins.
 	// 	NOTE: `pluginDir` is deprecated, use `library` instead.
 	pluginDirPtr *string
 }
 
+// stringList is the list of values specified by repeating a command line
+// option (Ex: "-library").
+type stringList []string
+
+// String returns the values as a comma separated string.
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ",")
+}
+
+// Set adds the specified value to the list.
+func (sl *stringList) Set(value string) error {
+	*sl = append(*sl, value)
+	return nil
+}
+
 // ListOptions are optional parameters related to list function.
 type ListOptions struct {
 	Type string
+	// Select selects a subset of plugins to list.
+	Select SelectOptions
 }
 
 // RunOptions are optional parameters related to run function.
 type RunOptions struct {
-	Library    string
+	Library string
+	// Libraries is the ordered list of plugins libraries with the highest
+	// 	precedence library first. When specified, Library is ignored while
+	// 	reading the plugins.
+	Libraries  []string
 	Type       string
 	Sequential bool
+	// FailFast stops starting new plugins after the first plugin failure.
+	FailFast bool
+	// TerminateOnAbort terminates the plugins being run when the run is
+	// aborted either due to FailFast or a plugin with fatal FailureMode.
+	TerminateOnAbort bool
+	// DryRun is exposed to the plugins as PM_DRY_RUN env value, so that the
+	// 	plugins could report the changes they'd make without making them.
+	DryRun bool
+	// RunID identifies the run, and is generated when not specified.
+	RunID string
+	// Select selects a subset of plugins to run. The plugins that are not
+	// 	selected are marked as skipped with "excluded" reason.
+	Select SelectOptions
+	// completedPlugins are the plugins run in the earlier phases, which the
+	// 	plugins of the current phase could depend on.
+	completedPlugins Plugins
+	// laterPlugins are the names of the plugins of the later phases, which
+	// 	the plugins of the current phase could be RequiredBy.
+	laterPlugins []string
 }
 
 // ListFromLibrary lists the plugin and its dependencies from the plugins
 // library path.
 func ListFromLibrary(pluginType, library string) error {
-	pluginsInfo, err := getPluginsInfoFromLibrary(pluginType, library)
+	return ListFromLibraries([]string{library}, ListOptions{Type: pluginType})
+}
+
+// ListFromLibraries lists the plugin and its dependencies from the plugins
+// libraries, where the libraries listed first have higher precedence.
+func ListFromLibraries(libraries []string, listOptions ListOptions) error {
+	pluginsInfo, err := getPluginsInfoFromLibraries(listOptions.Type, libraries)
 	if err != nil {
 		return err
 	}
 
-	listOptions := ListOptions{
-		Type: pluginType,
-	}
 	return list(pluginsInfo, listOptions)
 }
 
@@ -695,13 +1204,32 @@ func list(pluginsInfo Plugins, listOptions ListOptions) error {
 
 	var err error
 
-	err = initGraph(pluginType, pluginsInfo)
+	var excluded Plugins
+	if listOptions.Select.isSet() {
+		pluginsInfo, excluded, err = selectPlugins(pluginsInfo, listOptions.Select)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = initGraph(pluginType, append(append(Plugins{}, pluginsInfo...), excluded...))
 	if err != nil {
 		return err
 	}
+	for _, pInfo := range excluded {
+		updateGraph(pluginType, pInfo.Name, dStatusSkip, "")
+	}
+
+	_, depErr := validateDependencies(normalizePluginsInfo(pluginsInfo))
+	if dErr, ok := depErr.(*DependencyError); ok {
+		err = highlightDependencyIssues(pluginType, dErr)
+		if err != nil {
+			return err
+		}
+	}
 
 	logger.ConsoleInfo.Printf("The list of plugins are mapped in %s", getImagePath())
-	return nil
+	return depErr
 }
 
 func readFile(filePath string) (string, error) {
@@ -732,12 +1260,22 @@ func RegisterCommandOptions(progname string) {
 	CmdOptions.pluginTypePtr = CmdOptions.RunCmd.String(
 		"type",
 		"",
-		"Type of plugin.",
+		"Type of plugin.\nMultiple types could be specified as a comma separated list\n"+
+			"(Ex: 'preupgrade,prereboot') to run them as ordered phases.",
 	)
-	CmdOptions.libraryPtr = CmdOptions.RunCmd.String(
-		"library",
+	CmdOptions.phasesFilePtr = CmdOptions.RunCmd.String(
+		"phases",
 		"",
+		"File listing the plugin types to be run as ordered phases.\n"+
+			"When specified, '-type' is ignored.",
+	)
+	CmdOptions.RunCmd.Var(
+		&CmdOptions.libraries,
+		"library",
 		"Path of the plugins library.\nSets PM_LIBRARY env value.\n"+
+			"Can be repeated to specify multiple libraries, where the plugins\n"+
+			"of the libraries specified first override the same-named plugins\n"+
+			"of the libraries specified later.\n"+
 			"When '-plugins' is specified, only PM_LIBRARY env value is set. "+
 			"The plugin files are not read from library path.",
 	)
@@ -746,6 +1284,26 @@ func RegisterCommandOptions(progname string) {
 		false,
 		"Enforce running plugins in sequential.",
 	)
+	CmdOptions.failFast = CmdOptions.RunCmd.Bool(
+		"fail-fast",
+		false,
+		"Stop starting new plugins after the first plugin failure.\n"+
+			"The plugins that are not started are marked as skipped.",
+	)
+	CmdOptions.terminateOnAbort = CmdOptions.RunCmd.Bool(
+		"terminate-on-abort",
+		false,
+		"Terminate the plugins being run when the run is aborted\n"+
+			"(i.e., due to '-fail-fast' or a plugin with fatal FailureMode).",
+	)
+	CmdOptions.dryRun = CmdOptions.RunCmd.Bool(
+		"dry-run",
+		false,
+		"Run the plugins with PM_DRY_RUN env value set to true, so that\n"+
+			"the plugins could report the changes without making them.",
+	)
+	registerSelectCommandOptions(CmdOptions.RunCmd)
+	registerGraphCommandOptions(CmdOptions.RunCmd)
 	logger.RegisterCommandOptions(CmdOptions.RunCmd, map[string]string{
 		"log-dir":   config.GetLogDir(),
 		"log-file":  config.GetLogFile(),
@@ -766,17 +1324,233 @@ func RegisterCommandOptions(progname string) {
 		"",
 		"Type of plugin.",
 	)
-	CmdOptions.ListCmd.StringVar(
-		CmdOptions.libraryPtr,
+	CmdOptions.ListCmd.Var(
+		&CmdOptions.libraries,
 		"library",
-		"",
-		"Path of the plugins library.",
+		"Path of the plugins library.\nCan be repeated to specify multiple libraries, where the\n"+
+			"plugins of the libraries specified first have higher precedence.",
 	)
+	registerSelectCommandOptions(CmdOptions.ListCmd)
+	registerGraphCommandOptions(CmdOptions.ListCmd)
 	logger.RegisterCommandOptions(CmdOptions.ListCmd, map[string]string{
 		"log-dir":   config.GetLogDir(),
 		"log-file":  config.GetLogFile(),
 		"log-level": config.GetLogLevel(),
 	})
+
+	CmdOptions.ValidateCmd = flag.NewFlagSet(progname+" validate", flag.PanicOnError)
+	CmdOptions.ValidateCmd.StringVar(
+		CmdOptions.pluginTypePtr,
+		"type",
+		"",
+		"Type of plugin.\nWhen not specified, plugins of all types in the library are validated.",
+	)
+	CmdOptions.ValidateCmd.Var(
+		&CmdOptions.libraries,
+		"library",
+		"Path of the plugins library.\nCan be repeated to specify multiple libraries, where the\n"+
+			"plugins of the libraries specified first have higher precedence.",
+	)
+	logger.RegisterCommandOptions(CmdOptions.ValidateCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+	output.RegisterCommandOptions(CmdOptions.ValidateCmd, map[string]string{})
+
+	CmdOptions.ShowCmd = flag.NewFlagSet(progname+" show", flag.PanicOnError)
+	CmdOptions.ShowCmd.Var(
+		&CmdOptions.libraries,
+		"library",
+		"Path of the plugins library.\nCan be repeated to specify multiple libraries, where the\n"+
+			"plugins of the libraries specified first have higher precedence.",
+	)
+	logger.RegisterCommandOptions(CmdOptions.ShowCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+	output.RegisterCommandOptions(CmdOptions.ShowCmd, map[string]string{})
+
+	CmdOptions.HistoryCmd = flag.NewFlagSet(progname+" history", flag.PanicOnError)
+	CmdOptions.historyTypePtr = CmdOptions.HistoryCmd.String(
+		"type",
+		"",
+		"Type of the plugins whose runs are to be displayed.\n"+
+			"When not specified, the runs of all plugin types are displayed.",
+	)
+	CmdOptions.historyLastPtr = CmdOptions.HistoryCmd.Int(
+		"last",
+		0,
+		"Number of the most recent runs to be displayed.\n"+
+			"When not specified, all the recorded runs are displayed.",
+	)
+	logger.RegisterCommandOptions(CmdOptions.HistoryCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+	output.RegisterCommandOptions(CmdOptions.HistoryCmd, map[string]string{})
+
+	CmdOptions.ShowRunCmd = flag.NewFlagSet(progname+" show-run", flag.PanicOnError)
+	logger.RegisterCommandOptions(CmdOptions.ShowRunCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+	output.RegisterCommandOptions(CmdOptions.ShowRunCmd, map[string]string{})
+
+	CmdOptions.DiffRunsCmd = flag.NewFlagSet(progname+" diff-runs", flag.PanicOnError)
+	CmdOptions.diffThresholdPtr = CmdOptions.DiffRunsCmd.Float64(
+		"threshold",
+		50,
+		"Percentage by which the duration of a plugin should increase to be\n"+
+			"reported as a duration regression.",
+	)
+	CmdOptions.diffMinIncreasePtr = CmdOptions.DiffRunsCmd.Duration(
+		"min-increase",
+		time.Second,
+		"Minimum increase in the duration of a plugin to be reported as a\n"+
+			"duration regression.",
+	)
+	logger.RegisterCommandOptions(CmdOptions.DiffRunsCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+	output.RegisterCommandOptions(CmdOptions.DiffRunsCmd, map[string]string{})
+
+	CmdOptions.ReportCmd = flag.NewFlagSet(progname+" report", flag.PanicOnError)
+	CmdOptions.reportParallelismPtr = CmdOptions.ReportCmd.Int(
+		"parallelism",
+		0,
+		"Number of plugins that could be run at a time for estimating the wall time.\n"+
+			"When not specified, the parallelism is unlimited.",
+	)
+	registerGraphCommandOptions(CmdOptions.ReportCmd)
+	logger.RegisterCommandOptions(CmdOptions.ReportCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+	output.RegisterCommandOptions(CmdOptions.ReportCmd, map[string]string{})
+
+	CmdOptions.TimelineCmd = flag.NewFlagSet(progname+" timeline", flag.PanicOnError)
+	CmdOptions.timelineFilePtr = CmdOptions.TimelineCmd.String(
+		"timeline-file",
+		"",
+		"Name of the timeline image file.\n"+
+			"When not specified, it's generated next to the run result as <result>.timeline.svg.",
+	)
+	logger.RegisterCommandOptions(CmdOptions.TimelineCmd, map[string]string{
+		"log-dir":   config.GetLogDir(),
+		"log-file":  config.GetLogFile(),
+		"log-level": config.GetLogLevel(),
+	})
+
+	CmdOptions.StateCmds = map[string]*flag.FlagSet{}
+	for _, stateCmd := range []string{dStateCmdDisable, dStateCmdEnable,
+		dStateCmdMask, dStateCmdUnmask} {
+		f := flag.NewFlagSet(progname+" "+stateCmd, flag.PanicOnError)
+		f.Var(
+			&CmdOptions.libraries,
+			"library",
+			"Path of the plugins library.\nCan be repeated to specify multiple libraries, where the\n"+
+				"plugins of the libraries specified first have higher precedence.",
+		)
+		logger.RegisterCommandOptions(f, map[string]string{
+			"log-dir":   config.GetLogDir(),
+			"log-file":  config.GetLogFile(),
+			"log-level": config.GetLogLevel(),
+		})
+		CmdOptions.StateCmds[stateCmd] = f
+	}
+}
+
+// registerSelectCommandOptions registers the command line options to select
+// a subset of plugins.
+func registerSelectCommandOptions(f *flag.FlagSet) {
+	if CmdOptions.withDependencies == nil {
+		CmdOptions.withDependencies = new(bool)
+		CmdOptions.withDependents = new(bool)
+	}
+	f.Var(
+		&CmdOptions.only,
+		"only",
+		"Select only the plugins whose names match the pattern.\n"+
+			"The pattern is either a glob pattern (Ex: 'A/*'), or a regular expression\n"+
+			"prefixed with 're:' (Ex: 're:^(A|B)/'). Can be repeated.",
+	)
+	f.Var(
+		&CmdOptions.exclude,
+		"exclude",
+		"Exclude the plugins whose names match the pattern.\n"+
+			"The pattern format is same as '-only'. Can be repeated.\n"+
+			"The excluded plugins are marked as skipped with 'excluded' reason.",
+	)
+	f.Var(
+		&CmdOptions.tags,
+		"tags",
+		"Select only the plugins having any of the tags.\n"+
+			"Can be repeated, or specified as a comma separated list.",
+	)
+	f.Var(
+		&CmdOptions.excludeTags,
+		"exclude-tags",
+		"Exclude the plugins having any of the tags.\n"+
+			"Can be repeated, or specified as a comma separated list.",
+	)
+	f.BoolVar(
+		CmdOptions.withDependencies,
+		"with-dependencies",
+		false,
+		"Additionally select the plugins required by the selected plugins.",
+	)
+	f.BoolVar(
+		CmdOptions.withDependents,
+		"with-dependents",
+		false,
+		"Additionally select the plugins that require the selected plugins.",
+	)
+}
+
+// registerGraphCommandOptions registers the options for generating the graph
+// image with the command.
+func registerGraphCommandOptions(f *flag.FlagSet) {
+	f.StringVar(
+		&CmdOptions.graphRenderer,
+		"graph-renderer",
+		graphRendererDot,
+		"Renderer used for generating the graph image: "+graphRendererDot+" or "+graphRendererBuiltin+".\n"+
+			"The "+graphRendererDot+" renderer falls back to "+graphRendererBuiltin+
+			" when graphviz dot command is not present.",
+	)
+}
+
+// getSelectOptions returns the plugins selection specified on command line.
+func getSelectOptions() SelectOptions {
+	return SelectOptions{
+		Only:             CmdOptions.only,
+		Exclude:          CmdOptions.exclude,
+		Tags:             splitCommaSeparated(CmdOptions.tags),
+		ExcludeTags:      splitCommaSeparated(CmdOptions.excludeTags),
+		WithDependencies: *CmdOptions.withDependencies,
+		WithDependents:   *CmdOptions.withDependents,
+	}
+}
+
+// splitCommaSeparated returns the values after splitting the comma separated
+// values.
+func splitCommaSeparated(values []string) []string {
+	splitValues := []string{}
+	for _, value := range values {
+		for _, sv := range strings.Split(value, ",") {
+			if sv = strings.TrimSpace(sv); sv != "" {
+				splitValues = append(splitValues, sv)
+			}
+		}
+	}
+	return splitValues
 }
 
 // RunFromJSONStrOrFile runs the plugins based on dependencies specified in a
@@ -788,9 +1562,28 @@ func RunFromJSONStrOrFile(result *RunStatus, jsonStrOrFile string, runOptions Ru
 		result.StdOutErr = err.Error()
 		return err
 	}
+	setRunID(result, &runOptions)
+	startTime := time.Now()
+	defer func() {
+		saveRunTimeline(result)
+		saveRunRecord(result, runOptions, startTime)
+	}()
 	result.Type = pluginsInfo.Type
 	result.Library = pluginsInfo.Library
 	result.Plugins = pluginsInfo.Plugins
+	result.Phases = pluginsInfo.Phases
+	// INFO: The State of the plugins in json/file is ignored, as the plugins
+	// 	could have been disabled, enabled, masked or unmasked since then.
+	state, err := loadPluginsState()
+	if err != nil {
+		result.Status = dStatusFail
+		result.StdOutErr = err.Error()
+		return err
+	}
+	state.applyTo(result.Plugins)
+	for phaseIdx := range result.Phases {
+		state.applyTo(result.Phases[phaseIdx].Plugins)
+	}
 	// INFO: Override values of json/file with explicitly passed cmdline parameter. Else, set runOptions type from json/file.
 	if runOptions.Type != "" {
 		result.Type = runOptions.Type
@@ -802,14 +1595,44 @@ func RunFromJSONStrOrFile(result *RunStatus, jsonStrOrFile string, runOptions Ru
 	} else {
 		runOptions.Library = pluginsInfo.Library
 	}
+	if len(result.Phases) != 0 {
+		return runPhases(result, runOptions)
+	}
 	return run(result, runOptions)
 }
 
 // RunFromLibrary runs the specified plugin type plugins from the library.
+// When multiple plugin types are specified as a comma separated list, they're
+// run as ordered phases.
 func RunFromLibrary(result *RunStatus, pluginType string, runOptions RunOptions) error {
+	setRunID(result, &runOptions)
+	startTime := time.Now()
+	defer func() {
+		saveRunTimeline(result)
+		saveRunRecord(result, runOptions, startTime)
+	}()
 	result.Type = pluginType
 
-	var pluginsInfo, err = getPluginsInfoFromLibrary(pluginType, runOptions.Library)
+	libraries := runOptions.Libraries
+	if len(libraries) == 0 {
+		libraries = []string{runOptions.Library}
+	}
+	if types := getPhaseTypes(pluginType); len(types) > 1 {
+		result.Type = strings.Join(types, ",")
+		result.Library = runOptions.Library
+		for _, phaseType := range types {
+			pluginsInfo, err := getPluginsInfoFromLibraries(phaseType, libraries)
+			if err != nil {
+				result.Status = dStatusFail
+				result.StdOutErr = err.Error()
+				return err
+			}
+			result.Phases = append(result.Phases,
+				RunStatus{Type: phaseType, Plugins: pluginsInfo})
+		}
+		return runPhases(result, runOptions)
+	}
+	var pluginsInfo, err = getPluginsInfoFromLibraries(pluginType, libraries)
 	if err != nil {
 		result.Status = dStatusFail
 		result.StdOutErr = err.Error()
@@ -821,12 +1644,21 @@ func RunFromLibrary(result *RunStatus, pluginType string, runOptions RunOptions)
 	return run(result, runOptions)
 }
 
+// setRunID generates the run ID when it's not specified, and records it in
+// the run status.
+func setRunID(result *RunStatus, runOptions *RunOptions) {
+	if runOptions.RunID == "" {
+		runOptions.RunID = time.Now().UTC().Format("20060102T150405.000000000Z")
+	}
+	result.RunID = runOptions.RunID
+	result.DryRun = runOptions.DryRun
+}
+
 // run the specified plugins.
 func run(result *RunStatus, runOptions RunOptions) error {
 	logger.Debug.Printf("Entering run(%+v, %+v)...", result, runOptions)
 	defer logger.Debug.Println("Exiting run")
 	pluginType := runOptions.Type
-	sequential := runOptions.Sequential
 
 	if err := osutils.OsMkdirAll(config.GetPluginsLogDir(), 0755); nil != err {
 		err = logger.ConsoleError.PrintNReturnError(
@@ -837,13 +1669,46 @@ func run(result *RunStatus, runOptions RunOptions) error {
 		return err
 	}
 
-	initGraph(pluginType, result.Plugins)
+	pluginsInfo := result.Plugins
+	var excluded Plugins
+	if runOptions.Select.isSet() {
+		var err error
+		result.Plugins, excluded, err = selectPlugins(pluginsInfo, runOptions.Select)
+		if err != nil {
+			result.Plugins = pluginsInfo
+			result.Status = dStatusFail
+			result.StdOutErr = err.Error()
+			return err
+		}
+	}
+
+	initGraph(pluginType, append(append(Plugins{}, result.Plugins...), excluded...))
+	for _, pInfo := range excluded {
+		updateGraph(pluginType, pInfo.Name, dStatusSkip, "")
+	}
 
 	env := map[string]string{}
 	if runOptions.Library != "" {
 		env["PM_LIBRARY"] = runOptions.Library
 	}
-	status := executePlugins(&result.Plugins, sequential, env)
+	if len(runOptions.Libraries) != 0 {
+		env["PM_LIBRARIES"] = strings.Join(runOptions.Libraries, string(os.PathListSeparator))
+	}
+	result.RunID = runOptions.RunID
+	result.DryRun = runOptions.DryRun
+	env["PM_RUN_ID"] = runOptions.RunID
+	env["PM_LOG_DIR"] = config.GetPMLogDir()
+	env["PM_DRY_RUN"] = strconv.FormatBool(runOptions.DryRun)
+	// INFO: Record the run status, so that the plugin references to the
+	// 	plugins of this type could be checked in the later runs. A dry run
+	// 	is not recorded, as the plugins didn't make any changes.
+	if !runOptions.DryRun {
+		defer saveLastRunStatus(result)
+	}
+	status := executePlugins(&result.Plugins, runOptions, env)
+	if runOptions.Select.isSet() {
+		result.Plugins = mergeSelectedPlugins(pluginsInfo, result.Plugins, excluded)
+	}
 	if status != true {
 		result.Status = dStatusFail
 		err := fmt.Errorf("Running %s plugins: %s", pluginType, dStatusFail)
@@ -894,6 +1759,54 @@ func ScanCommandOptions(options map[string]interface{}) error {
 			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
 		}
 
+	case "validate":
+		err := CmdOptions.ValidateCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case "show":
+		err := CmdOptions.ShowCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case "history":
+		err := CmdOptions.HistoryCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case "show-run":
+		err := CmdOptions.ShowRunCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case "diff-runs":
+		err := CmdOptions.DiffRunsCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case "report":
+		err := CmdOptions.ReportCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case "timeline":
+		err := CmdOptions.TimelineCmd.Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
+	case dStateCmdDisable, dStateCmdEnable, dStateCmdMask, dStateCmdUnmask:
+		err := CmdOptions.StateCmds[cmd].Parse(os.Args[cmdIndex+1:])
+		if err != nil {
+			logger.Error.Printf("Command arguments parse error, cmd=%s, err=%s", cmd, err.Error())
+		}
+
 	case "help":
 		subcmd := ""
 		if len(os.Args) == cmdIndex+2 {
@@ -911,8 +1824,8 @@ func ScanCommandOptions(options map[string]interface{}) error {
 	}
 
 	// Override `pm.config.yaml` value with command-line arguments.
-	if *CmdOptions.libraryPtr != "" {
-		config.SetPluginsLibrary(*CmdOptions.libraryPtr)
+	if len(CmdOptions.libraries) != 0 {
+		config.SetPluginsLibraries(CmdOptions.libraries)
 	}
 	myLogFile := "./"
 	if logger.GetLogDir() != "" {
@@ -958,27 +1871,114 @@ func ScanCommandOptions(options map[string]interface{}) error {
 		}
 	}
 
+	if CmdOptions.graphRenderer != "" {
+		if CmdOptions.graphRenderer != graphRendererDot &&
+			CmdOptions.graphRenderer != graphRendererBuiltin {
+			return logger.ConsoleError.PrintNReturnError(
+				"Unknown graph renderer '%s'. Specify either %s or %s.",
+				CmdOptions.graphRenderer, graphRendererDot, graphRendererBuiltin)
+		}
+		graphRenderer = CmdOptions.graphRenderer
+	}
+
 	var err error
 	pluginType := *CmdOptions.pluginTypePtr
+	if cmd == "run" && *CmdOptions.phasesFilePtr != "" {
+		phases, err := readPhasesFile(*CmdOptions.phasesFilePtr)
+		if err != nil {
+			return err
+		}
+		pluginType = strings.Join(phases, ",")
+	}
+	if cmd == "validate" {
+		validateOptions := ValidateOptions{}
+		if pluginType != "" {
+			validateOptions.Types = strings.Split(pluginType, ",")
+		}
+		report, err := Validate(config.GetPluginsLibraries(), validateOptions)
+		output.Write(report)
+		return err
+	}
+	if cmd == "show" {
+		pluginsDetails, err := Show(config.GetPluginsLibraries(), CmdOptions.ShowCmd.Args())
+		output.Write(pluginsDetails)
+		return err
+	}
+	if cmd == "history" {
+		records, err := History(*CmdOptions.historyTypePtr, *CmdOptions.historyLastPtr)
+		output.Write(records)
+		return err
+	}
+	if cmd == "show-run" {
+		args := CmdOptions.ShowRunCmd.Args()
+		if len(args) != 1 {
+			return logger.ConsoleError.PrintNReturnError(
+				"Specify the run ID listed by the history command.")
+		}
+		record, err := ShowRun(args[0])
+		output.Write(record)
+		return err
+	}
+	if cmd == "diff-runs" {
+		args := CmdOptions.DiffRunsCmd.Args()
+		if len(args) != 2 {
+			return logger.ConsoleError.PrintNReturnError(
+				"Specify the old and the new run results to be compared.")
+		}
+		diff, err := DiffRuns(args[0], args[1], DiffOptions{
+			Threshold:   *CmdOptions.diffThresholdPtr,
+			MinIncrease: *CmdOptions.diffMinIncreasePtr,
+		})
+		output.Write(diff)
+		return err
+	}
+	if cmd == "report" {
+		args := CmdOptions.ReportCmd.Args()
+		if len(args) != 1 {
+			return logger.ConsoleError.PrintNReturnError(
+				"Specify the run result to be analyzed.")
+		}
+		report, err := Report(args[0], ReportOptions{
+			Parallelism: *CmdOptions.reportParallelismPtr,
+		})
+		output.Write(report)
+		return err
+	}
+	if cmd == "timeline" {
+		args := CmdOptions.TimelineCmd.Args()
+		if len(args) != 1 {
+			return logger.ConsoleError.PrintNReturnError(
+				"Specify the run result whose timeline is to be generated.")
+		}
+		_, err := Timeline(args[0], *CmdOptions.timelineFilePtr)
+		return err
+	}
+	if stateCmd, ok := CmdOptions.StateCmds[cmd]; ok {
+		return ChangePluginsState(config.GetPluginsLibraries(), cmd, stateCmd.Args())
+	}
 	if *CmdOptions.pluginsPtr != "" {
 		jsonStrOrFile := *CmdOptions.pluginsPtr
 		switch cmd {
 		case "list":
 			err = ListFromJSONStrOrFile(jsonStrOrFile,
-				ListOptions{Type: pluginType})
+				ListOptions{Type: pluginType, Select: getSelectOptions()})
 
 		case "run":
 			pmstatus := RunStatus{}
 			runOptions := RunOptions{
-				Type:       pluginType,
-				Sequential: *CmdOptions.sequential,
+				Type:             pluginType,
+				Sequential:       *CmdOptions.sequential,
+				FailFast:         *CmdOptions.failFast,
+				TerminateOnAbort: *CmdOptions.terminateOnAbort,
+				DryRun:           *CmdOptions.dryRun,
+				Select:           getSelectOptions(),
 			}
 			// NOTE: When '-plugins' info is passed as str or file, don't use
 			// 	Library from config.
 			// 	The config file is expected to have some library path, and that
 			//  may not be applicable for this set of inputs. So, set/use
 			//  "Library" value only if it's passed as cmdline argument.
-			if *CmdOptions.libraryPtr != "" {
+			if len(CmdOptions.libraries) != 0 {
 				runOptions.Library = config.GetPluginsLibrary()
 			}
 			err = RunFromJSONStrOrFile(&pmstatus, jsonStrOrFile, runOptions)
@@ -987,13 +1987,19 @@ func ScanCommandOptions(options map[string]interface{}) error {
 	} else if pluginType != "" {
 		switch cmd {
 		case "list":
-			err = ListFromLibrary(pluginType, config.GetPluginsLibrary())
+			err = ListFromLibraries(config.GetPluginsLibraries(),
+				ListOptions{Type: pluginType, Select: getSelectOptions()})
 
 		case "run":
 			pmstatus := RunStatus{}
 			err = RunFromLibrary(&pmstatus, pluginType,
 				RunOptions{Library: config.GetPluginsLibrary(),
-					Sequential: *CmdOptions.sequential})
+					Libraries:        config.GetPluginsLibraries(),
+					Sequential:       *CmdOptions.sequential,
+					FailFast:         *CmdOptions.failFast,
+					TerminateOnAbort: *CmdOptions.terminateOnAbort,
+					DryRun:           *CmdOptions.dryRun,
+					Select:           getSelectOptions()})
 			output.Write(pmstatus)
 		}
 	}
@@ -1013,8 +2019,19 @@ Usage:
 
 The commands are:
 
+	diff-runs	compare two run results for regressions.
+	disable		disable plugins, so that they're treated as succeeded without being run.
+	enable		enable the disabled plugins.
+	history		list the recorded runs.
 	list 		lists plugins and its dependencies of specified type in an image.
+	mask		mask plugins, so that they and their dependents are skipped.
+	report		analyze the critical path and the slack of the plugins of a run result.
 	run 		run plugins of specified type.
+	show		show plugins info after applying drop-in overrides.
+	show-run	show the recorded run along with the status of its plugins.
+	timeline	generate the timeline image of a run result.
+	unmask		unmask the masked plugins.
+	validate	validate plugins of specified type (or all types) in the library.
 	version		print Plugin Manager version.
 
 Use "PROGNAME ` + subcmd + ` help [command]" for more information about a command.
@@ -1027,6 +2044,22 @@ Use "PROGNAME ` + subcmd + ` help [command]" for more information about a comman
 		CmdOptions.ListCmd.Usage()
 	case "run":
 		CmdOptions.RunCmd.Usage()
+	case "validate":
+		CmdOptions.ValidateCmd.Usage()
+	case "show":
+		CmdOptions.ShowCmd.Usage()
+	case "history":
+		CmdOptions.HistoryCmd.Usage()
+	case "show-run":
+		CmdOptions.ShowRunCmd.Usage()
+	case "diff-runs":
+		CmdOptions.DiffRunsCmd.Usage()
+	case "report":
+		CmdOptions.ReportCmd.Usage()
+	case "timeline":
+		CmdOptions.TimelineCmd.Usage()
+	case dStateCmdDisable, dStateCmdEnable, dStateCmdMask, dStateCmdUnmask:
+		CmdOptions.StateCmds[subcmd].Usage()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown help topic `%s`. Run '%s'.", subcmd, progname+" help")
 		fmt.Println()
@@ -0,0 +1,17 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+//go:build !unix
+
+package pm
+
+import (
+	"os/exec"
+)
+
+// setPluginProcessGroup keeps the default termination of the plugin.
+//
+//	NOTE: Process groups are supported only on Unix, so only the plugin
+//	process is killed when the plugin gets terminated, and not the processes
+//	started by it.
+func setPluginProcessGroup(cmd *exec.Cmd) {
+}
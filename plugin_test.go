@@ -3,11 +3,13 @@
 package pm
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/VeritasOS/plugin-manager/config"
 	logger "github.com/VeritasOS/plugin-manager/utils/log"
@@ -34,8 +36,28 @@ func initTestLogging( /*t *testing.T*/ ) {
 	}
 }
 
-func init() {
+// TestMain runs the tests with the logs (including the plugin logs and the
+// graphs) and the state of the runs kept in a temporary dir, so that the tests
+// neither leave files in the package dir nor change the state of the system.
+func TestMain(m *testing.M) {
+	testDir, err := os.MkdirTemp("", "pm-test")
+	if err != nil {
+		fmt.Printf("Failed to create test dir, err=[%v]", err)
+		os.Exit(-1)
+	}
+	logDir := filepath.Join(testDir, "log")
+	if err = os.MkdirAll(logDir, 0755); err != nil {
+		fmt.Printf("Failed to create test log dir, err=[%v]", err)
+		os.Exit(-1)
+	}
+	config.SetPMLogDir(logDir)
+	config.SetStateDir(filepath.Join(testDir, "state"))
 	initTestLogging()
+
+	code := m.Run()
+	logger.DeInitLogger()
+	os.RemoveAll(testDir)
+	os.Exit(code)
 }
 
 func Test_getPluginFiles(t *testing.T) {
@@ -483,6 +505,21 @@ ExecStart=/bin/echo "Running D...!"
 				ExecStart: "/bin/echo \"Running D...!\"",
 			},
 		},
+		{
+			name: "Plugin file with failure mode",
+			fileContents: `
+Description=Collecting diagnostics
+ExecStart=/bin/echo "Collecting...!"
+FailureMode=warn
+SkipOnIgnoredFailure=yes
+`,
+			pluginInfo: Plugin{
+				Description:          "Collecting diagnostics",
+				ExecStart:            "/bin/echo \"Collecting...!\"",
+				FailureMode:          "warn",
+				SkipOnIgnoredFailure: true,
+			},
+		},
 		{
 			name: "Plugin file with colon in desc",
 			fileContents: `
@@ -493,6 +530,91 @@ ExecStart=/bin/echo "Running A & B...!"
 				Description: "Applying \"A:B\" settings",
 				ExecStart:   "/bin/echo \"Running A & B...!\"",
 			},
+		}, {
+			name: "Plugin file with line continuations",
+			fileContents: `
+Description=Applying "D" \
+  settings
+ExecStart=/bin/echo \
+  # Comment lines within continuation lines are skipped.
+  "Running D...!"
+Requires=a.test \
+  b.test
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"D\" settings",
+				ExecStart:   "/bin/echo \"Running D...!\"",
+				Requires:    []string{"a.test", "b.test"},
+			},
+		},
+		{
+			name: "Sectioned plugin file",
+			fileContents: `
+[Unit]
+Description=Applying "D" settings
+Requires=a.test b.test
+
+[Exec]
+ExecStart=/bin/echo "Running D...!"
+
+[Install]
+RequiredBy=c.test
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"D\" settings",
+				Requires:    []string{"a.test", "b.test"},
+				RequiredBy:  []string{"c.test"},
+				ExecStart:   "/bin/echo \"Running D...!\"",
+			},
+		},
+		{
+			name: "Plugin file with repeated and reset list keys",
+			fileContents: `
+Description=Applying "D" settings
+Requires=a.test  b.test	
+Requires=c.test
+RequiredBy=x.test
+RequiredBy=
+RequiredBy=y.test
+ExecStart=/bin/echo "Running D...!"
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"D\" settings",
+				Requires:    []string{"a.test", "b.test", "c.test"},
+				RequiredBy:  []string{"y.test"},
+				ExecStart:   "/bin/echo \"Running D...!\"",
+			},
+		},
+		{
+			name: "Plugin file with tags",
+			fileContents: `
+[Unit]
+Description=Applying "D" settings
+Tags=storage quick
+Tags=network
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"D\" settings",
+				Tags:        []string{"storage", "quick", "network"},
+			},
+		},
+		{
+			name: "Template plugin file with instances",
+			fileContents: `
+[Unit]
+Description=Checking disk %i
+[Exec]
+ExecStart=/usr/bin/check-disk /dev/%i
+[Install]
+Instances=sda sdb
+InstancesGlob=/sys/block/sd*
+`,
+			pluginInfo: Plugin{
+				Description:   "Checking disk %i",
+				ExecStart:     "/usr/bin/check-disk /dev/%i",
+				Instances:     []string{"sda", "sdb"},
+				InstancesGlob: "/sys/block/sd*",
+			},
 		},
 	}
 
@@ -520,6 +642,7 @@ func Test_executePlugins(t *testing.T) {
 		name       string
 		pluginInfo Plugins
 		sequential bool
+		failFast   bool
 		want       want
 	}{
 		{
@@ -781,6 +904,252 @@ func Test_executePlugins(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Record blocking dependencies and root cause of skipped plugins",
+			pluginInfo: Plugins{
+				{
+					Name:        "A/a.test",
+					Description: "Applying \"A\" settings",
+					ExecStart:   "exit 1",
+				},
+				{
+					Name:        "B/b.test",
+					Description: "Applying \"B\" settings",
+					ExecStart:   "/bin/echo \"Running B...!\"",
+				},
+				{
+					Name:        "D/d.test",
+					Description: "Applying \"D\" settings",
+					Requires:    []string{"A/a.test"},
+					ExecStart:   "/bin/echo \"Running D...!\"",
+				},
+				{
+					Name:        "E/e.test",
+					Description: "Applying \"E\" settings",
+					Requires:    []string{"B/b.test", "D/d.test"},
+					ExecStart:   "/bin/echo \"Running E...!\"",
+				},
+			},
+			want: want{
+				returnStatus: false,
+				psStatus: Plugins{
+					{
+						Name:   "A/a.test",
+						Status: "Failed",
+					},
+					{
+						Name:   "B/b.test",
+						Status: "Succeeded",
+					},
+					{
+						Name:      "D/d.test",
+						Status:    "Skipped",
+						Reason:    "dependencies not met: A/a.test; root cause: A/a.test",
+						BlockedBy: []string{"A/a.test"},
+					},
+					{
+						Name:      "E/e.test",
+						Status:    "Skipped",
+						Reason:    "dependencies not met: D/d.test; root cause: A/a.test",
+						BlockedBy: []string{"D/d.test"},
+					},
+				},
+			},
+		},
+		{
+			name: "Disabled plugins pass while masked plugins skip their dependents",
+			pluginInfo: Plugins{
+				{
+					Name:        "A/a.test",
+					Description: "Applying \"A\" settings",
+					ExecStart:   "exit 1",
+					State:       "disabled",
+				},
+				{
+					Name:        "B/b.test",
+					Description: "Applying \"B\" settings",
+					ExecStart:   "/bin/echo \"Running B...!\"",
+					State:       "masked",
+				},
+				{
+					Name:        "D/d.test",
+					Description: "Applying \"D\" settings",
+					Requires:    []string{"A/a.test"},
+					ExecStart:   "/bin/echo \"Running D...!\"",
+				},
+				{
+					Name:        "E/e.test",
+					Description: "Applying \"E\" settings",
+					Requires:    []string{"B/b.test", "D/d.test"},
+					ExecStart:   "/bin/echo \"Running E...!\"",
+				},
+			},
+			want: want{
+				returnStatus: true,
+				psStatus: Plugins{
+					{
+						Name:   "A/a.test",
+						Status: "Succeeded",
+						Reason: "disabled",
+					},
+					{
+						Name:   "B/b.test",
+						Status: "Skipped",
+						Reason: "masked",
+					},
+					{
+						Name:   "D/d.test",
+						Status: "Succeeded",
+					},
+					{
+						Name:      "E/e.test",
+						Status:    "Skipped",
+						Reason:    "dependencies not met: B/b.test; root cause: B/b.test",
+						BlockedBy: []string{"B/b.test"},
+					},
+				},
+			},
+		},
+		{
+			name: "Ignored failure neither fails the run nor skips dependents",
+			pluginInfo: Plugins{
+				{
+					Name:        "A/a.test",
+					Description: "Applying \"A\" settings",
+					ExecStart:   "exit 1",
+					FailureMode: "ignore",
+				},
+				{
+					Name:        "D/d.test",
+					Description: "Applying \"D\" settings",
+					Requires:    []string{"A/a.test"},
+					ExecStart:   "/bin/echo \"Running D...!\"",
+				},
+			},
+			want: want{
+				returnStatus: true,
+				psStatus: Plugins{
+					{
+						Name:   "A/a.test",
+						Status: "FailedIgnored",
+					},
+					{
+						Name:   "D/d.test",
+						Status: "Succeeded",
+					},
+				},
+			},
+		},
+		{
+			name: "Skip dependent of warned failure when it opts in",
+			pluginInfo: Plugins{
+				{
+					Name:        "A/a.test",
+					Description: "Applying \"A\" settings",
+					ExecStart:   "exit 1",
+					FailureMode: "warn",
+				},
+				{
+					Name:                 "D/d.test",
+					Description:          "Applying \"D\" settings",
+					Requires:             []string{"A/a.test"},
+					SkipOnIgnoredFailure: true,
+					ExecStart:            "/bin/echo \"Running D...!\"",
+				},
+			},
+			want: want{
+				returnStatus: true,
+				psStatus: Plugins{
+					{
+						Name:   "A/a.test",
+						Status: "FailedIgnored",
+					},
+					{
+						Name:   "D/d.test",
+						Status: "Skipped",
+					},
+				},
+			},
+		},
+		{
+			name: "Fatal failure stops scheduling remaining plugins",
+			pluginInfo: Plugins{
+				{
+					Name:        "B/b.test",
+					Description: "Applying \"B\" settings",
+					ExecStart:   "/bin/sleep 0.5",
+				},
+				{
+					Name:        "A/a.test",
+					Description: "Applying \"A\" settings",
+					ExecStart:   "exit 1",
+					FailureMode: "fatal",
+				},
+				{
+					Name:        "C/c.test",
+					Description: "Applying \"C\" settings",
+					Requires:    []string{"B/b.test"},
+					ExecStart:   "/bin/echo \"Running C...!\"",
+				},
+			},
+			want: want{
+				returnStatus: false,
+				psStatus: Plugins{
+					{
+						Name:   "B/b.test",
+						Status: "Succeeded",
+					},
+					{
+						Name:   "A/a.test",
+						Status: "Failed",
+					},
+					{
+						Name:   "C/c.test",
+						Status: "Skipped",
+					},
+				},
+			},
+		},
+		{
+			name: "Fail fast stops scheduling remaining plugins",
+			pluginInfo: Plugins{
+				{
+					Name:        "B/b.test",
+					Description: "Applying \"B\" settings",
+					ExecStart:   "/bin/sleep 0.5",
+				},
+				{
+					Name:        "A/a.test",
+					Description: "Applying \"A\" settings",
+					ExecStart:   "exit 1",
+				},
+				{
+					Name:        "C/c.test",
+					Description: "Applying \"C\" settings",
+					Requires:    []string{"B/b.test"},
+					ExecStart:   "/bin/echo \"Running C...!\"",
+				},
+			},
+			failFast: true,
+			want: want{
+				returnStatus: false,
+				psStatus: Plugins{
+					{
+						Name:   "B/b.test",
+						Status: "Succeeded",
+					},
+					{
+						Name:   "A/a.test",
+						Status: "Failed",
+					},
+					{
+						Name:   "C/c.test",
+						Status: "Skipped",
+						Reason: "aborted after failure of A/a.test",
+					},
+				},
+			},
+		},
 	}
 
 	initGraphConfig(config.GetPMLogFile())
@@ -789,7 +1158,9 @@ func Test_executePlugins(t *testing.T) {
 		for _, tt.sequential = range []bool{false, true} {
 			t.Run(tt.name+fmt.Sprintf("(sequential=%v)", tt.sequential),
 				func(t *testing.T) {
-					res := executePlugins(&tt.pluginInfo, tt.sequential, map[string]string{})
+					res := executePlugins(&tt.pluginInfo,
+						RunOptions{Sequential: tt.sequential, FailFast: tt.failFast},
+						map[string]string{})
 					// t.Logf("res: %+v, expected: %v", res, tt.want.returnStatus)
 					if res != tt.want.returnStatus {
 						t.Errorf("Return value: got %+v, want %+v",
@@ -811,6 +1182,19 @@ func Test_executePlugins(t *testing.T) {
 								tt.pluginInfo[i].StdOutErr,
 								tt.want.psStatus[i].StdOutErr)
 						}
+						if len(tt.want.psStatus[i].BlockedBy) != 0 &&
+							reflect.DeepEqual(tt.pluginInfo[i].BlockedBy,
+								tt.want.psStatus[i].BlockedBy) == false {
+							t.Errorf("Plugins %s BlockedBy: got %+v, want %+v",
+								tt.pluginInfo[i].Name,
+								tt.pluginInfo[i].BlockedBy, tt.want.psStatus[i].BlockedBy)
+						}
+						if tt.want.psStatus[i].Reason != "" &&
+							tt.pluginInfo[i].Reason != tt.want.psStatus[i].Reason {
+							t.Errorf("Plugins %s Reason: got %+v, want %+v",
+								tt.pluginInfo[i].Name,
+								tt.pluginInfo[i].Reason, tt.want.psStatus[i].Reason)
+						}
 					}
 				},
 			)
@@ -818,6 +1202,111 @@ func Test_executePlugins(t *testing.T) {
 	}
 }
 
+func Test_executePlugins_terminateOnAbort(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	// INFO: The script runs sleep as a child process, which keeps the plugin
+	// 	output open unless it's terminated along with the plugin.
+	script := filepath.Join(t.TempDir(), "sleep.sh")
+	if err := os.WriteFile(script, []byte("sleep 30\necho done\n"), 0755); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+	pluginsInfo := Plugins{
+		{
+			Name:        "A/a.test",
+			Description: "Applying \"A\" settings",
+			ExecStart:   "exit 1",
+		},
+		{
+			Name:        "B/b.test",
+			Description: "Applying \"B\" settings",
+			ExecStart:   "/bin/sh " + script,
+		},
+		{
+			Name:        "C/c.test",
+			Description: "Applying \"C\" settings",
+			Requires:    []string{"B/b.test"},
+			ExecStart:   "/bin/echo \"Running C...!\"",
+		},
+	}
+	want := map[string][]string{
+		"A/a.test": {dStatusFail, ""},
+		"B/b.test": {dStatusFail, "terminated after failure of A/a.test"},
+		"C/c.test": {dStatusSkip, "aborted after failure of A/a.test"},
+	}
+
+	initGraphConfig(config.GetPMLogFile())
+	start := time.Now()
+	res := executePlugins(&pluginsInfo,
+		RunOptions{FailFast: true, TerminateOnAbort: true},
+		map[string]string{})
+	if res != false {
+		t.Errorf("Return value: got %+v, want %+v", res, false)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("Running plugins were not terminated, took %v", time.Since(start))
+	}
+	for _, p := range pluginsInfo {
+		if p.Status != want[p.Name][0] ||
+			(want[p.Name][1] != "" && p.Reason != want[p.Name][1]) {
+			t.Errorf("Plugin %s: got (%s, %s), want %v", p.Name, p.Status, p.Reason, want[p.Name])
+		}
+	}
+}
+
+func Test_executePlugins_timeoutAndDisabled(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	pluginsInfo := Plugins{
+		{
+			Name:        "A/a.test",
+			Description: "Applying \"A\" settings",
+			ExecStart:   "/bin/sleep 30",
+			TimeoutSec:  1,
+		},
+		{
+			Name:        "B/b.test",
+			Description: "Applying \"B\" settings",
+			ExecStart:   "/bin/sleep 30",
+			TimeoutSec:  1,
+			FailureMode: failureModeIgnore,
+		},
+		{
+			Name:        "C/c.test",
+			Description: "Applying \"C\" settings",
+			Requires:    []string{"B/b.test"},
+			ExecStart:   "exit 1",
+			Disabled:    true,
+		},
+	}
+	want := map[string][]string{
+		"A/a.test": {dStatusFail, "timed out after 1s"},
+		"B/b.test": {dStatusFailIgnored, "timed out after 1s"},
+		"C/c.test": {dStatusOk, dStateDisabled},
+	}
+
+	initGraphConfig(config.GetPMLogFile())
+	start := time.Now()
+	res := executePlugins(&pluginsInfo, RunOptions{}, map[string]string{})
+	if res != false {
+		t.Errorf("Return value: got %+v, want %+v", res, false)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("Plugins were not terminated on timeout, took %v", time.Since(start))
+	}
+	for _, p := range pluginsInfo {
+		if p.Status != want[p.Name][0] || p.Reason != want[p.Name][1] {
+			t.Errorf("Plugin %s: got (%s, %s), want %v", p.Name, p.Status, p.Reason, want[p.Name])
+		}
+	}
+}
+
 func Test_getPluginsInfoFromJSONStrOrFile(t *testing.T) {
 	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
 		t.Skip("Not applicable while running integration tests.")
@@ -1033,3 +1522,215 @@ func Test_normalizePluginsInfo(t *testing.T) {
 		})
 	}
 }
+
+func Test_getPluginsInfoFromLibraries(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	siteLibrary := createTestLibrary(t, map[string]string{
+		"A/a.test":      "Description=Site A\n",
+		"C/c.test.yaml": "description: Site C\n",
+	})
+	baseLibrary := createTestLibrary(t, map[string]string{
+		"A/a.test": "Description=Base A\n",
+		"B/b.test": "Description=Base B\nRequires=C/c.test\n",
+	})
+	missingLibrary := filepath.FromSlash(t.TempDir() + "/missing/")
+
+	tests := []struct {
+		name      string
+		libraries []string
+		want      Plugins
+		wantErr   bool
+	}{
+		{
+			name:      "Higher precedence library overrides same-named plugins",
+			libraries: []string{siteLibrary, missingLibrary, baseLibrary},
+			want: Plugins{
+				{Name: "A/a.test", Description: "Site A", Library: siteLibrary},
+				{Name: "B/b.test", Description: "Base B", Requires: []string{"C/c.test"}, Library: baseLibrary},
+				{Name: "C/c.test", Description: "Site C", Library: siteLibrary},
+			},
+		},
+		{
+			name:      "Only library doesn't exist",
+			libraries: []string{missingLibrary},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPluginsInfoFromLibraries("test", tt.libraries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPluginsInfoFromLibraries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPluginsInfoFromLibraries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getLibraryFiles(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"README":                        "Files directly under library are skipped.\n",
+		"A/a.test":                      "Description=A\n",
+		"A/a.test.d/10-a.conf":          "Description=A drop-in\n",
+		"P/B/b.test":                    "Description=B\nRequires=A/a.test\n",
+		"P/Q/C/c.test":                  "Description=C\nRequires=P/B/b.test\n",
+		"external/E/e.test":             "Description=E\n",
+		"external/E/e.test.d/10-e.conf": "Description=E drop-in\n",
+		"init.d/i.test":                 "Description=I\n",
+		"init.d/I/j.test":               "Description=J\n",
+	})
+	// Symlinked component directory, and a symlink loop.
+	if err := os.Symlink(filepath.FromSlash(library+"external/E"), filepath.FromSlash(library+"P/E")); err != nil {
+		t.Fatalf("os.Symlink() err=%s", err)
+	}
+	if err := os.Symlink(filepath.FromSlash(library+"P"), filepath.FromSlash(library+"P/Q/loop")); err != nil {
+		t.Fatalf("os.Symlink() err=%s", err)
+	}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     []string
+	}{
+		{
+			name:     "Component directories only",
+			maxDepth: 1,
+			want:     []string{"A/a.test", "init.d/i.test"},
+		},
+		{
+			name:     "Nested component directories",
+			maxDepth: 2,
+			want: []string{"A/a.test", "P/B/b.test", "P/E/e.test", "external/E/e.test",
+				"init.d/I/j.test", "init.d/i.test"},
+		},
+		{
+			name:     "Deeply nested directories with symlink loop",
+			maxDepth: 10,
+			want: []string{"A/a.test", "P/B/b.test", "P/E/e.test", "P/Q/C/c.test",
+				"external/E/e.test", "init.d/I/j.test", "init.d/i.test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getLibraryFiles(library, tt.maxDepth)
+			if err != nil {
+				t.Fatalf("getLibraryFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getLibraryFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	defer config.SetLibraryDepth(config.GetLibraryDepth())
+	config.SetLibraryDepth(3)
+	pluginsInfo, err := getPluginsInfoFromLibrary("test", library)
+	if err != nil {
+		t.Fatalf("getPluginsInfoFromLibrary() error = %v", err)
+	}
+	if _, err = validateDependencies(normalizePluginsInfo(pluginsInfo)); err != nil {
+		t.Errorf("validateDependencies() error = %v", err)
+	}
+}
+
+func TestRunFromLibrary_env(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.test": "Description=A\nExecStart=${PM_LIBRARY}/A/a.sh ${PM_PLUGIN_NAME} ${PM_ATTEMPT}\n",
+		"A/a.sh": "#!/bin/sh\necho $1 $2\n" +
+			"echo $PM_PLUGIN_NAME $PM_PLUGIN_TYPE $PM_RUN_ID $PM_ATTEMPT $PM_DRY_RUN\n" +
+			"echo $PM_STATE_DIR\ntest -d $PM_STATE_DIR && echo state dir exists\n" +
+			"echo $PM_LOG_DIR\n",
+	})
+	stateDir := t.TempDir()
+	defer config.SetStateDir(config.GetStateDir())
+	config.SetStateDir(stateDir)
+
+	want := []string{
+		"A/a.test 1",
+		"A/a.test test test-run 1 true",
+		filepath.Join(stateDir, "plugins", "A", "a.test") + string(os.PathSeparator),
+		"state dir exists",
+		config.GetPMLogDir(),
+	}
+	result := RunStatus{}
+	err := RunFromLibrary(&result, "test",
+		RunOptions{Library: library, DryRun: true, RunID: "test-run"})
+	if err != nil {
+		t.Fatalf("RunFromLibrary() error = %v", err)
+	}
+	if result.RunID != "test-run" || !result.DryRun {
+		t.Errorf("RunFromLibrary() RunID = %s, DryRun = %v, want test-run, true",
+			result.RunID, result.DryRun)
+	}
+	if len(result.Plugins) != 1 || !reflect.DeepEqual(result.Plugins[0].StdOutErr, want) ||
+		result.Plugins[0].Attempt != 1 {
+		t.Fatalf("RunFromLibrary() Plugins = %+v, want StdOutErr %q of attempt 1", result.Plugins, want)
+	}
+
+	// Rerunning the plugins using the run result increments the attempt.
+	resultFile := filepath.Join(t.TempDir(), "result.json")
+	bytes, _ := json.Marshal(result)
+	if err = os.WriteFile(resultFile, bytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+	rerun := RunStatus{}
+	if err = RunFromJSONStrOrFile(&rerun, resultFile, RunOptions{}); err != nil {
+		t.Fatalf("RunFromJSONStrOrFile() error = %v", err)
+	}
+	if rerun.RunID == "" || rerun.RunID == result.RunID || rerun.DryRun {
+		t.Errorf("RunFromJSONStrOrFile() RunID = %s, DryRun = %v, want a new run ID",
+			rerun.RunID, rerun.DryRun)
+	}
+	if len(rerun.Plugins) != 1 || rerun.Plugins[0].Attempt != 2 ||
+		rerun.Plugins[0].StdOutErr[0] != "A/a.test 2" {
+		t.Errorf("RunFromJSONStrOrFile() Plugins = %+v, want attempt 2", rerun.Plugins)
+	}
+}
+
+func TestRunFromJSONStrOrFile_rerunBlocked(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	defer config.SetStateDir(config.GetStateDir())
+	config.SetStateDir(t.TempDir())
+
+	// INFO: The result of a run in which B was skipped as A failed.
+	previous := RunStatus{Type: "test", Plugins: Plugins{
+		{Name: "A/a.test", Description: "A", ExecStart: "/bin/true", Status: dStatusFail},
+		{Name: "B/b.test", Description: "B", ExecStart: "/bin/true", Requires: []string{"A/a.test"},
+			Status: dStatusSkip, BlockedBy: []string{"A/a.test"},
+			Reason: "dependencies not met: A/a.test; root cause: A/a.test"},
+	}}
+	bytes, err := json.Marshal(previous)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	rerun := RunStatus{}
+	if err = RunFromJSONStrOrFile(&rerun, string(bytes), RunOptions{}); err != nil {
+		t.Fatalf("RunFromJSONStrOrFile() error = %v", err)
+	}
+	for _, pInfo := range rerun.Plugins {
+		if pInfo.Status != dStatusOk || pInfo.BlockedBy != nil || pInfo.Reason != "" {
+			t.Errorf("RunFromJSONStrOrFile() plugin %s = (%s, %v, %s), want %s without BlockedBy",
+				pInfo.Name, pInfo.Status, pInfo.BlockedBy, pInfo.Reason, dStatusOk)
+		}
+	}
+}
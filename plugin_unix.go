@@ -0,0 +1,21 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+//go:build unix
+
+package pm
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setPluginProcessGroup runs the plugin in its own process group, and kills
+// the process group when the plugin gets terminated, so that the processes
+// started by the plugin (Ex: "sh -c <cmd>") are also terminated, and don't keep
+// the plugin output open.
+func setPluginProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
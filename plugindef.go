@@ -0,0 +1,128 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm plugindef is used for parsing the YAML/JSON plugin definition
+// files.
+package pm
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+// pluginDefFormats are the extensions of the plugin definition files, which
+// describe the plugin using the Plugin schema instead of the unit file format.
+//
+//	NOTE: The plugin definition file is named by appending the format
+//	extension to the plugin file name i.e., "A/a.preupgrade.yaml" defines the
+//	"A/a.preupgrade" plugin.
+var pluginDefFormats = []string{".yaml", ".yml", ".json"}
+
+// getPluginDefFormat returns the format extension of a plugin definition file,
+// and empty string for a plugin unit file.
+func getPluginDefFormat(file string) string {
+	ext := path.Ext(file)
+	if containsString(pluginDefFormats, ext) {
+		return ext
+	}
+	return ""
+}
+
+// getPluginName returns the name of the plugin defined in the specified
+// plugin file.
+func getPluginName(file string) string {
+	return strings.TrimSuffix(file, getPluginDefFormat(file))
+}
+
+// parsePluginFile parses the contents of either a plugin unit file or a plugin
+// definition file based on the file extension.
+func parsePluginFile(file, fileContents string, up *unitParser) (Plugin, error) {
+	format := getPluginDefFormat(file)
+	if format == "" {
+		return up.parse(fileContents)
+	}
+	return up.parseDef(fileContents, format)
+}
+
+// parseDef parses the plugin definition file contents in the specified format.
+//
+//	NOTE: In strict mode, unknown fields are reported as issues. The run time
+//	fields like Status, Reason and StdOutErr are ignored.
+func (up *unitParser) parseDef(fileContents, format string) (Plugin, error) {
+	logger.Debug.Println("Entering parseDef")
+	defer logger.Debug.Println("Exiting parseDef")
+
+	pluginInfo := Plugin{}
+	var err error
+	if format == ".json" {
+		dec := json.NewDecoder(strings.NewReader(fileContents))
+		if up.strict {
+			dec.DisallowUnknownFields()
+		}
+		err = dec.Decode(&pluginInfo)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewBufferString(fileContents))
+		dec.KnownFields(up.strict)
+		err = dec.Decode(&pluginInfo)
+	}
+	if err != nil && strings.TrimSpace(fileContents) != "" {
+		logger.Error.Printf("Failed to decode plugin definition %s, err=%s", up.file, err.Error())
+		if ferr := up.fail(0, "Plugin definition is not in expected format. Error: %s", err.Error()); ferr != nil {
+			return Plugin{}, ferr
+		}
+	}
+
+	switch pluginInfo.FailureMode {
+	case "", failureModeIgnore, failureModeWarn, failureModeFatal:
+	default:
+		if err := up.fail(0,
+			"Invalid FailureMode '%s'. Supported values are '%s', '%s' and '%s'.",
+			pluginInfo.FailureMode, failureModeIgnore, failureModeWarn, failureModeFatal); err != nil {
+			return Plugin{}, err
+		}
+		pluginInfo.FailureMode = ""
+	}
+	if pluginInfo.TimeoutSec < 0 {
+		if err := up.fail(0, "Invalid TimeoutSec '%d'. Expected a non-negative number of seconds.",
+			pluginInfo.TimeoutSec); err != nil {
+			return Plugin{}, err
+		}
+		pluginInfo.TimeoutSec = 0
+	}
+	for key, isSet := range map[string]bool{
+		"Description":          pluginInfo.Description != "",
+		"ExecStart":            pluginInfo.ExecStart != "",
+		"RequiredBy":           len(pluginInfo.RequiredBy) != 0,
+		"Requires":             len(pluginInfo.Requires) != 0,
+		"Tags":                 len(pluginInfo.Tags) != 0,
+		"Instances":            len(pluginInfo.Instances) != 0,
+		"InstancesGlob":        pluginInfo.InstancesGlob != "",
+		"InstancesFrom":        pluginInfo.InstancesFrom != "",
+		"FailureMode":          pluginInfo.FailureMode != "",
+		"SkipOnIgnoredFailure": pluginInfo.SkipOnIgnoredFailure,
+		"TimeoutSec":           pluginInfo.TimeoutSec != 0,
+		"Disabled":             pluginInfo.Disabled,
+	} {
+		if isSet {
+			up.setSource(key, 0, false)
+		}
+	}
+	return Plugin{
+		Description:          pluginInfo.Description,
+		ExecStart:            pluginInfo.ExecStart,
+		RequiredBy:           pluginInfo.RequiredBy,
+		Requires:             pluginInfo.Requires,
+		Tags:                 pluginInfo.Tags,
+		Instances:            pluginInfo.Instances,
+		InstancesGlob:        pluginInfo.InstancesGlob,
+		InstancesFrom:        pluginInfo.InstancesFrom,
+		FailureMode:          pluginInfo.FailureMode,
+		SkipOnIgnoredFailure: pluginInfo.SkipOnIgnoredFailure,
+		TimeoutSec:           pluginInfo.TimeoutSec,
+		Disabled:             pluginInfo.Disabled,
+	}, nil
+}
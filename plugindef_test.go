@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_getPluginsInfoFromLibrary_definitionFiles(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		pluginsInfo Plugins
+		wantErr     string
+	}{
+		{
+			name: "Mixed plugin file formats",
+			files: map[string]string{
+				"A/a.test": "Description=A\nExecStart=/bin/echo A\n",
+				"B/b.test.yaml": `
+description: B
+execstart: /bin/echo B
+requires:
+  - A/a.test
+failuremode: warn
+status: Failed
+`,
+				"C/c.test.json": `{"Description": "C", "RequiredBy": ["A/a.test"], "SkipOnIgnoredFailure": true}`,
+				"D/d.check.yml": "description: D\n",
+			},
+			pluginsInfo: Plugins{
+				{Name: "A/a.test", Description: "A", ExecStart: "/bin/echo A"},
+				{Name: "B/b.test", Description: "B", ExecStart: "/bin/echo B",
+					Requires: []string{"A/a.test"}, FailureMode: failureModeWarn},
+				{Name: "C/c.test", Description: "C", RequiredBy: []string{"A/a.test"},
+					SkipOnIgnoredFailure: true},
+			},
+		},
+		{
+			name: "Plugin defined in multiple formats",
+			files: map[string]string{
+				"A/a.test":      "Description=A\n",
+				"A/a.test.json": `{"Description": "A"}`,
+			},
+			wantErr: "Plugin A/a.test is defined in both A/a.test and A/a.test.json.",
+		},
+		{
+			name: "Invalid failure mode",
+			files: map[string]string{
+				"A/a.test.json": `{"Description": "A", "FailureMode": "never"}`,
+			},
+			wantErr: "A/a.test.json: Invalid FailureMode 'never'. Supported values are 'ignore', 'warn' and 'fatal'.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := createTestLibrary(t, tt.files)
+			got, err := getPluginsInfoFromLibrary("test", library)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("getPluginsInfoFromLibrary() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getPluginsInfoFromLibrary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.pluginsInfo) {
+				t.Errorf("getPluginsInfoFromLibrary() = %+v, want %+v", got, tt.pluginsInfo)
+			}
+		})
+	}
+}
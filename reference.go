@@ -0,0 +1,192 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm reference is used for the dependencies on the plugins of other
+// plugin types, and for recording the last run status of each plugin type
+// to check such dependencies.
+package pm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+// pluginRefPrefix is the prefix of a plugin reference i.e.,
+// "@<type>:<component>/<plugin-file>" (Ex: "@preupgrade:A/a.preupgrade").
+//
+//	NOTE: A plugin reference is a dependency on a plugin of another type, which
+//	is met when the referenced plugin succeeded either in an earlier phase of
+//	the same run, or in the last run of its type.
+const pluginRefPrefix = "@"
+
+// isPluginRef returns whether the dependency is a plugin reference.
+func isPluginRef(dependency string) bool {
+	return strings.HasPrefix(dependency, pluginRefPrefix)
+}
+
+// parsePluginRef returns the plugin type and the plugin name of the plugin
+// reference.
+func parsePluginRef(ref string) (string, string, error) {
+	pluginType, pName, found := strings.Cut(strings.TrimPrefix(ref, pluginRefPrefix), ":")
+	if !found || pluginType == "" || pName == "" || getPluginType(pName) != pluginType {
+		return pluginType, pName, fmt.Errorf(
+			"Invalid plugin reference '%s'. Expected format is '%s<type>:<component>/<plugin-file>.<type>'.",
+			ref, pluginRefPrefix)
+	}
+	return pluginType, pName, nil
+}
+
+// removePluginRefs returns the dependencies without the plugin references.
+func removePluginRefs(dependencies []string) []string {
+	deps := []string{}
+	for _, dep := range dependencies {
+		if !isPluginRef(dep) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// getPluginRefName returns the name of the referenced plugin, or the
+// dependency itself when it's not a plugin reference.
+func getPluginRefName(dependency string) string {
+	if !isPluginRef(dependency) {
+		return dependency
+	}
+	_, pName, _ := strings.Cut(dependency, ":")
+	return pName
+}
+
+// getLastRunFile returns the file recording the last run status of the
+// plugin type.
+func getLastRunFile(pluginType string) string {
+	return config.GetStateDir() + "last-run." + pluginType + ".yaml"
+}
+
+// saveLastRunStatus records the run status of the plugin type, so that the
+// plugin references to its plugins could be checked in the later runs.
+//
+//	NOTE: Failing to record the status doesn't fail the run. The status of a
+//	run without a plugin type (i.e., of the plugins specified using -plugins)
+//	is not recorded, as the plugins can't be referenced by their type.
+func saveLastRunStatus(result *RunStatus) {
+	if result.Type == "" {
+		logger.Info.Println("Not recording the run status as the plugin type is not set.")
+		return
+	}
+	lastRunFile := getLastRunFile(result.Type)
+	lastRun := *result
+	// INFO: The plugins excluded from the run retain their status recorded in
+	// 	the earlier runs, so that running a subset of the plugins doesn't
+	// 	override the status of the plugins that it didn't run.
+	if prevRun, lerr := loadLastRunStatus(result.Type); lerr == nil {
+		prevPlugins := map[string]Plugin{}
+		for _, pInfo := range prevRun.Plugins {
+			prevPlugins[pInfo.Name] = pInfo
+		}
+		lastRun.Plugins = Plugins{}
+		for _, pInfo := range result.Plugins {
+			if prevInfo, ok := prevPlugins[pInfo.Name]; ok &&
+				pInfo.Status == dStatusSkip && pInfo.Reason == dReasonExcluded {
+				pInfo = prevInfo
+			}
+			lastRun.Plugins = append(lastRun.Plugins, pInfo)
+		}
+	}
+	bytes, err := yaml.Marshal(lastRun)
+	if err != nil {
+		logger.Warning.Printf("Failed to marshal the run status. Error: %s", err.Error())
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(lastRunFile), 0755); err != nil {
+		logger.Warning.Printf("Failed to create state dir %s. Error: %s",
+			filepath.Dir(lastRunFile), err.Error())
+		return
+	}
+	if err = os.WriteFile(lastRunFile, bytes, 0644); err != nil {
+		logger.Warning.Printf("Failed to record the run status in %s. Error: %s",
+			lastRunFile, err.Error())
+	}
+}
+
+// loadLastRunStatus returns the recorded last run status of the plugin type.
+func loadLastRunStatus(pluginType string) (RunStatus, error) {
+	var result RunStatus
+	bytes, err := os.ReadFile(filepath.Clean(getLastRunFile(pluginType)))
+	if err != nil {
+		return result, err
+	}
+	err = yaml.Unmarshal(bytes, &result)
+	return result, err
+}
+
+// isDependencyMet returns whether the completed plugin doesn't block the
+// plugin requiring it. isRef indicates that the completed plugin is required
+// using a plugin reference.
+//
+//	NOTE: The plugins excluded from the run don't block the plugins requiring
+//	them, except when they're referenced, as a plugin reference asserts that
+//	the referenced plugin succeeded.
+func isDependencyMet(cInfo Plugin, pInfo Plugin, isRef bool) bool {
+	switch cInfo.Status {
+	case dStatusOk:
+		return true
+	case dStatusFailIgnored:
+		return !pInfo.SkipOnIgnoredFailure
+	case dStatusSkip:
+		return !isRef && cInfo.Reason == dReasonExcluded
+	}
+	return false
+}
+
+// pluginRefResolver resolves the plugin references using the plugins
+// completed in the earlier phases and the last run status of each type.
+type pluginRefResolver struct {
+	completed map[string]Plugin
+	lastRuns  map[string]map[string]Plugin
+}
+
+// newPluginRefResolver returns the resolver for the completed plugins.
+func newPluginRefResolver(completedPlugins Plugins) pluginRefResolver {
+	resolver := pluginRefResolver{
+		completed: map[string]Plugin{},
+		lastRuns:  map[string]map[string]Plugin{},
+	}
+	for _, pInfo := range completedPlugins {
+		resolver.completed[pInfo.Name] = pInfo
+	}
+	return resolver
+}
+
+// resolve returns the status of the referenced plugin, and whether it's
+// known.
+func (resolver pluginRefResolver) resolve(ref string) (Plugin, bool) {
+	pluginType, pName, err := parsePluginRef(ref)
+	if err != nil {
+		logger.Warning.Println(err.Error())
+		return Plugin{}, false
+	}
+	if cInfo, ok := resolver.completed[pName]; ok {
+		return cInfo, true
+	}
+	lastRun, ok := resolver.lastRuns[pluginType]
+	if !ok {
+		lastRun = map[string]Plugin{}
+		result, lerr := loadLastRunStatus(pluginType)
+		if lerr != nil {
+			logger.Warning.Printf("Failed to read the last run status of %s plugins. Error: %s",
+				pluginType, lerr.Error())
+		}
+		for _, pInfo := range result.Plugins {
+			lastRun[pInfo.Name] = pInfo
+		}
+		resolver.lastRuns[pluginType] = lastRun
+	}
+	pInfo, ok := lastRun[pName]
+	return pInfo, ok
+}
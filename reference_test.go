@@ -0,0 +1,182 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func Test_parsePluginRef(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		ref      string
+		wantType string
+		wantName string
+		wantErr  bool
+	}{
+		{ref: "@preupgrade:A/a.preupgrade", wantType: "preupgrade", wantName: "A/a.preupgrade"},
+		{ref: "@preupgrade:A/a.prereboot", wantType: "preupgrade", wantName: "A/a.prereboot", wantErr: true},
+		{ref: "@preupgrade", wantType: "preupgrade", wantErr: true},
+		{ref: "@:A/a.preupgrade", wantName: "A/a.preupgrade", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			gotType, gotName, err := parsePluginRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePluginRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotType != tt.wantType || gotName != tt.wantName {
+				t.Errorf("parsePluginRef() = (%s, %s), want (%s, %s)",
+					gotType, gotName, tt.wantType, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestRunFromLibrary_pluginRefs(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.pre":  "Description=A\nExecStart=/bin/echo A\n",
+		"D/d.pre":  "Description=D\nExecStart=/bin/echo D\n",
+		"B/b.post": "Description=B\nExecStart=/bin/echo B\nRequires=@pre:A/a.pre\n",
+		"C/c.post": "Description=C\nExecStart=/bin/echo C\nRequires=B/b.post\n",
+	})
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+
+	tests := []struct {
+		name       string
+		pluginType string
+		only       []string
+		dryRun     bool
+		wantStatus map[string]string
+		wantReason map[string]string
+	}{
+		{
+			name:       "Referenced plugin didn't run",
+			pluginType: "post",
+			wantStatus: map[string]string{"B/b.post": dStatusSkip, "C/c.post": dStatusSkip},
+			wantReason: map[string]string{
+				"B/b.post": "dependencies not met: @pre:A/a.pre; root cause: @pre:A/a.pre",
+				"C/c.post": "dependencies not met: B/b.post; root cause: @pre:A/a.pre",
+			},
+		},
+		{
+			name:       "Dry run referenced plugin type",
+			pluginType: "pre",
+			dryRun:     true,
+			wantStatus: map[string]string{"A/a.pre": dStatusOk, "D/d.pre": dStatusOk},
+		},
+		{
+			name:       "Referenced plugin succeeded only in a dry run",
+			pluginType: "post",
+			wantStatus: map[string]string{"B/b.post": dStatusSkip, "C/c.post": dStatusSkip},
+			wantReason: map[string]string{
+				"B/b.post": "dependencies not met: @pre:A/a.pre; root cause: @pre:A/a.pre",
+			},
+		},
+		{
+			name:       "Run referenced plugin type excluding referenced plugin",
+			pluginType: "pre",
+			only:       []string{"D/*"},
+			wantStatus: map[string]string{"A/a.pre": dStatusSkip, "D/d.pre": dStatusOk},
+		},
+		{
+			name:       "Referenced plugin was excluded in the last run",
+			pluginType: "post",
+			wantStatus: map[string]string{"B/b.post": dStatusSkip, "C/c.post": dStatusSkip},
+			wantReason: map[string]string{
+				"B/b.post": "dependencies not met: @pre:A/a.pre; root cause: @pre:A/a.pre",
+			},
+		},
+		{
+			name:       "Run referenced plugin type",
+			pluginType: "pre",
+			wantStatus: map[string]string{"A/a.pre": dStatusOk, "D/d.pre": dStatusOk},
+		},
+		{
+			name:       "Referenced plugin succeeded in the last run",
+			pluginType: "post",
+			wantStatus: map[string]string{"B/b.post": dStatusOk, "C/c.post": dStatusOk},
+		},
+		{
+			name:       "Run referenced plugin type again excluding referenced plugin",
+			pluginType: "pre",
+			only:       []string{"D/*"},
+			wantStatus: map[string]string{"A/a.pre": dStatusSkip, "D/d.pre": dStatusOk},
+		},
+		{
+			name:       "Referenced plugin succeeded in an earlier run",
+			pluginType: "post",
+			wantStatus: map[string]string{"B/b.post": dStatusOk, "C/c.post": dStatusOk},
+		},
+		{
+			name:       "Referenced plugin succeeded in an earlier phase",
+			pluginType: "pre,post",
+			wantStatus: map[string]string{"A/a.pre": dStatusOk, "B/b.post": dStatusOk, "C/c.post": dStatusOk,
+				"D/d.pre": dStatusOk},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RunStatus{}
+			RunFromLibrary(&result, tt.pluginType, RunOptions{Library: library,
+				Select: SelectOptions{Only: tt.only}, DryRun: tt.dryRun})
+			plugins := result.Plugins
+			for _, phase := range result.Phases {
+				plugins = append(plugins, phase.Plugins...)
+			}
+			for _, pInfo := range plugins {
+				if pInfo.Status != tt.wantStatus[pInfo.Name] {
+					t.Errorf("Plugin %s Status = %s, want %s",
+						pInfo.Name, pInfo.Status, tt.wantStatus[pInfo.Name])
+				}
+				if want, ok := tt.wantReason[pInfo.Name]; ok && pInfo.Reason != want {
+					t.Errorf("Plugin %s Reason = %s, want %s", pInfo.Name, pInfo.Reason, want)
+				}
+			}
+		})
+	}
+
+	if err := ListFromLibrary("post", library); err != nil {
+		t.Errorf("ListFromLibrary() error = %v", err)
+	}
+}
+
+func TestRunFromJSONStrOrFile_noLastRunWithoutType(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	stateDir := t.TempDir()
+	defer config.SetStateDir(config.GetStateDir())
+	config.SetStateDir(stateDir)
+
+	result := RunStatus{}
+	err := RunFromJSONStrOrFile(&result,
+		`{"Plugins": [{"Name": "A/a.test", "Description": "A", "ExecStart": "/bin/true"}]}`,
+		RunOptions{})
+	if err != nil {
+		t.Fatalf("RunFromJSONStrOrFile() error = %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(stateDir, "last-run.*"))
+	if err != nil {
+		t.Fatalf("filepath.Glob() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("RunFromJSONStrOrFile() recorded the last run status in %v, want none", files)
+	}
+}
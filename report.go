@@ -0,0 +1,452 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm report is used for analyzing the durations of the plugins of a
+// run i.e., the critical path through the dependencies, the slack of each
+// plugin, and the wall time achievable with a given parallelism.
+package pm
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// ReportOptions are the optional parameters to analyze the run.
+type ReportOptions struct {
+	// Parallelism is the number of plugins that could be run at a time for
+	// 	estimating the wall time. When not specified, the parallelism is
+	// 	unlimited.
+	Parallelism int
+}
+
+// PluginTiming is the timing analysis of a plugin of the run. The start times
+// are relative to the start of the run in seconds.
+type PluginTiming struct {
+	Plugin  string
+	Status  string
+	Seconds float64
+	// EarliestStart is the earliest time at which the plugin could start,
+	// 	i.e., when all its required plugins complete.
+	EarliestStart float64
+	// LatestStart is the latest time at which the plugin could start without
+	// 	delaying the run.
+	LatestStart float64
+	// Slack is the time by which the plugin could be delayed (or its duration
+	// 	could increase) without delaying the run.
+	Slack float64
+	// Critical indicates that the plugin is on the critical path.
+	Critical bool `yaml:",omitempty" json:",omitempty"`
+}
+
+// ComponentTiming is the timing analysis of the plugins of a component.
+type ComponentTiming struct {
+	Component string
+	Plugins   int
+	// Seconds is the total duration of the plugins of the component.
+	Seconds float64
+	// CriticalSeconds is the total duration of the plugins of the component,
+	// 	which are on the critical path.
+	CriticalSeconds float64
+}
+
+// RunReport is the timing analysis of a run.
+type RunReport struct {
+	Result string
+	// ActualSeconds is the wall time of the run i.e., from the start of the
+	// 	first plugin to the end of the last plugin.
+	ActualSeconds float64
+	// SequentialSeconds is the wall time when the plugins are run in sequence.
+	SequentialSeconds float64
+	// CriticalPathSeconds is the wall time when the parallelism is unlimited.
+	CriticalPathSeconds float64
+	// EstimatedSeconds is the wall time estimated for the Parallelism.
+	EstimatedSeconds float64
+	Parallelism      int `yaml:",omitempty" json:",omitempty"`
+	CriticalPath     []string
+	Plugins          []PluginTiming
+	Components       []ComponentTiming
+}
+
+// runDAG is the dependency graph of the plugins of a run.
+type runDAG struct {
+	// order is the plugins in a topological order.
+	order []string
+	// plugins is the run status of each plugin.
+	plugins map[string]Plugin
+	// requires is the plugins required by each plugin, including the plugins
+	// 	of the earlier phase.
+	requires map[string][]string
+	// dependencies is the plugins required by each plugin as per its
+	// 	Requires and RequiredBy.
+	dependencies map[string][]string
+	// durations is the duration of each plugin, which is zero for the
+	// 	plugins that didn't run.
+	durations map[string]time.Duration
+}
+
+// getRunDAG returns the dependency graph of the plugins of the run.
+//
+//	NOTE: The phases are run one after another, so the plugins of a phase
+//	require all the plugins of the earlier phase. The plugin references are
+//	ignored, as the referenced plugins aren't part of the run.
+func getRunDAG(result RunStatus) (runDAG, error) {
+	dag := runDAG{
+		plugins:      map[string]Plugin{},
+		requires:     map[string][]string{},
+		dependencies: map[string][]string{},
+		durations:    map[string]time.Duration{},
+	}
+	phases := result.Phases
+	if len(phases) == 0 {
+		phases = []RunStatus{result}
+	}
+	for _, pInfo := range getRunPlugins(result) {
+		dag.plugins[pInfo.Name] = pInfo
+		dag.durations[pInfo.Name], _ = getPluginDuration(pInfo)
+	}
+	earlierPhase := []string{}
+	for _, phase := range phases {
+		phasePlugins := []string{}
+		for _, nPInfo := range normalizePluginsInfo(phase.Plugins) {
+			phasePlugins = append(phasePlugins, nPInfo.Name)
+			requires := append([]string{}, earlierPhase...)
+			for _, rs := range nPInfo.Requires {
+				if _, ok := dag.plugins[rs]; !ok {
+					continue
+				}
+				dag.dependencies[nPInfo.Name] = append(dag.dependencies[nPInfo.Name], rs)
+				if !containsString(requires, rs) {
+					requires = append(requires, rs)
+				}
+			}
+			dag.requires[nPInfo.Name] = requires
+		}
+		earlierPhase = phasePlugins
+	}
+
+	// INFO: Order the plugins such that each plugin is after the plugins it
+	// 	requires, while choosing the plugins in the order of their names.
+	pending := map[string]int{}
+	requiredBy := map[string][]string{}
+	ready := []string{}
+	for pName, requires := range dag.requires {
+		pending[pName] = len(requires)
+		for _, rs := range requires {
+			requiredBy[rs] = append(requiredBy[rs], pName)
+		}
+		if len(requires) == 0 {
+			ready = append(ready, pName)
+		}
+	}
+	for len(ready) != 0 {
+		sort.Strings(ready)
+		pName := ready[0]
+		ready = ready[1:]
+		dag.order = append(dag.order, pName)
+		for _, rby := range requiredBy[pName] {
+			pending[rby]--
+			if pending[rby] == 0 {
+				ready = append(ready, rby)
+			}
+		}
+	}
+	if len(dag.order) != len(dag.requires) {
+		return dag, logger.ConsoleError.PrintNReturnError(
+			"The plugins of the run result have cyclic dependencies.")
+	}
+	return dag, nil
+}
+
+// getRunWallTime returns the wall time of the run i.e., from the start of the
+// first plugin to the end of the last plugin.
+func getRunWallTime(dag runDAG) time.Duration {
+	var start, end time.Time
+	for _, pInfo := range dag.plugins {
+		if _, ran := getPluginDuration(pInfo); !ran {
+			continue
+		}
+		if start.IsZero() || pInfo.StartTime.Before(start) {
+			start = pInfo.StartTime
+		}
+		if pInfo.EndTime.After(end) {
+			end = pInfo.EndTime
+		}
+	}
+	return end.Sub(start)
+}
+
+// estimateWallTime returns the wall time of running the plugins with the
+// parallelism, when the ready plugins with the longest remaining path are run
+// first.
+func estimateWallTime(dag runDAG, remaining map[string]time.Duration, parallelism int) time.Duration {
+	if parallelism <= 0 {
+		parallelism = len(dag.order)
+	}
+	pending := map[string]int{}
+	requiredBy := map[string][]string{}
+	ready := []string{}
+	for _, pName := range dag.order {
+		pending[pName] = len(dag.requires[pName])
+		for _, rs := range dag.requires[pName] {
+			requiredBy[rs] = append(requiredBy[rs], pName)
+		}
+		if pending[pName] == 0 {
+			ready = append(ready, pName)
+		}
+	}
+	now := time.Duration(0)
+	running := map[string]time.Duration{}
+	for len(ready) != 0 || len(running) != 0 {
+		sort.SliceStable(ready, func(i, j int) bool {
+			if remaining[ready[i]] != remaining[ready[j]] {
+				return remaining[ready[i]] > remaining[ready[j]]
+			}
+			return ready[i] < ready[j]
+		})
+		for len(ready) != 0 && len(running) < parallelism {
+			running[ready[0]] = now + dag.durations[ready[0]]
+			ready = ready[1:]
+		}
+		next := time.Duration(-1)
+		for _, end := range running {
+			if next == -1 || end < next {
+				next = end
+			}
+		}
+		now = next
+		for pName, end := range running {
+			if end != now {
+				continue
+			}
+			delete(running, pName)
+			for _, rby := range requiredBy[pName] {
+				pending[rby]--
+				if pending[rby] == 0 {
+					ready = append(ready, rby)
+				}
+			}
+		}
+	}
+	return now
+}
+
+// analyzeRun returns the timing analysis of the run.
+func analyzeRun(result RunStatus, reportOptions ReportOptions) (RunReport, error) {
+	report := RunReport{Parallelism: reportOptions.Parallelism}
+	dag, err := getRunDAG(result)
+	if err != nil {
+		return report, err
+	}
+
+	// INFO: The earliest finish of a plugin is its duration after the
+	// 	earliest finish of its required plugins, and the run completes with
+	// 	the latest of them.
+	earliestStart := map[string]time.Duration{}
+	var total, sequential time.Duration
+	for _, pName := range dag.order {
+		for _, rs := range dag.requires[pName] {
+			if finish := earliestStart[rs] + dag.durations[rs]; finish > earliestStart[pName] {
+				earliestStart[pName] = finish
+			}
+		}
+		if finish := earliestStart[pName] + dag.durations[pName]; finish > total {
+			total = finish
+		}
+		sequential += dag.durations[pName]
+	}
+	// INFO: The latest start of a plugin is its duration before the latest
+	// 	start of the plugins requiring it, and the remaining path of a plugin
+	// 	is the time from its start till the end of the run.
+	latestStart := map[string]time.Duration{}
+	remaining := map[string]time.Duration{}
+	for idx := len(dag.order) - 1; idx >= 0; idx-- {
+		pName := dag.order[idx]
+		if _, ok := latestStart[pName]; !ok {
+			latestStart[pName] = total
+		}
+		latestStart[pName] -= dag.durations[pName]
+		remaining[pName] += dag.durations[pName]
+		for _, rs := range dag.requires[pName] {
+			if ls, ok := latestStart[rs]; !ok || latestStart[pName] < ls {
+				latestStart[rs] = latestStart[pName]
+			}
+			if remaining[pName] > remaining[rs] {
+				remaining[rs] = remaining[pName]
+			}
+		}
+	}
+
+	// INFO: Trace the critical path back from the plugin finishing last,
+	// 	through the required plugins finishing last.
+	last := ""
+	for _, pName := range dag.order {
+		if last == "" || earliestStart[pName]+dag.durations[pName] >
+			earliestStart[last]+dag.durations[last] {
+			last = pName
+		}
+	}
+	for pName := last; pName != ""; {
+		report.CriticalPath = append([]string{pName}, report.CriticalPath...)
+		prev := ""
+		for _, rs := range dag.requires[pName] {
+			if earliestStart[rs]+dag.durations[rs] != earliestStart[pName] {
+				continue
+			}
+			if prev == "" || rs < prev {
+				prev = rs
+			}
+		}
+		pName = prev
+	}
+
+	components := map[string]*ComponentTiming{}
+	for _, pName := range dag.order {
+		critical := containsString(report.CriticalPath, pName)
+		report.Plugins = append(report.Plugins, PluginTiming{
+			Plugin:        pName,
+			Status:        dag.plugins[pName].Status,
+			Seconds:       dag.durations[pName].Seconds(),
+			EarliestStart: earliestStart[pName].Seconds(),
+			LatestStart:   latestStart[pName].Seconds(),
+			Slack:         (latestStart[pName] - earliestStart[pName]).Seconds(),
+			Critical:      critical,
+		})
+		component := path.Dir(pName)
+		if _, ok := components[component]; !ok {
+			components[component] = &ComponentTiming{Component: component}
+		}
+		components[component].Plugins++
+		components[component].Seconds += dag.durations[pName].Seconds()
+		if critical {
+			components[component].CriticalSeconds += dag.durations[pName].Seconds()
+		}
+	}
+	sort.SliceStable(report.Plugins, func(i, j int) bool {
+		return report.Plugins[i].EarliestStart < report.Plugins[j].EarliestStart
+	})
+	for _, component := range components {
+		report.Components = append(report.Components, *component)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		ci, cj := report.Components[i], report.Components[j]
+		if ci.CriticalSeconds != cj.CriticalSeconds {
+			return ci.CriticalSeconds > cj.CriticalSeconds
+		}
+		if ci.Seconds != cj.Seconds {
+			return ci.Seconds > cj.Seconds
+		}
+		return ci.Component < cj.Component
+	})
+
+	report.ActualSeconds = getRunWallTime(dag).Seconds()
+	report.SequentialSeconds = sequential.Seconds()
+	report.CriticalPathSeconds = total.Seconds()
+	report.EstimatedSeconds = estimateWallTime(dag, remaining, reportOptions.Parallelism).Seconds()
+	return report, nil
+}
+
+// getCriticalEdges returns the dependencies between the consecutive plugins
+// of the critical path, as the consecutive plugins of different phases may
+// not have any dependency between them.
+func getCriticalEdges(dag runDAG, criticalPath []string) [][2]string {
+	edges := [][2]string{}
+	for idx := 1; idx < len(criticalPath); idx++ {
+		if containsString(dag.dependencies[criticalPath[idx]], criticalPath[idx-1]) {
+			edges = append(edges, [2]string{criticalPath[idx-1], criticalPath[idx]})
+		}
+	}
+	return edges
+}
+
+// formatSeconds returns the seconds as a duration rounded to milliseconds.
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+// displayRunReport displays the timing analysis of the run as a report.
+func displayRunReport(report RunReport) {
+	parallelism := "unlimited"
+	if report.Parallelism > 0 {
+		parallelism = fmt.Sprint(report.Parallelism)
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Run result:\t%s\n", report.Result)
+	fmt.Fprintf(w, "Actual wall time:\t%s\n", formatSeconds(report.ActualSeconds))
+	fmt.Fprintf(w, "Sequential time:\t%s\n", formatSeconds(report.SequentialSeconds))
+	fmt.Fprintf(w, "Critical path time:\t%s\n", formatSeconds(report.CriticalPathSeconds))
+	fmt.Fprintf(w, "Estimated wall time:\t%s (parallelism: %s)\n",
+		formatSeconds(report.EstimatedSeconds), parallelism)
+	fmt.Fprintf(w, "\nCritical path:\n  %s\n", strings.Join(report.CriticalPath, " -> "))
+
+	fmt.Fprintln(w, "\nPLUGIN\tSTATUS\tDURATION\tEARLIEST START\tLATEST START\tSLACK\tCRITICAL")
+	for _, timing := range report.Plugins {
+		critical := ""
+		if timing.Critical {
+			critical = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", timing.Plugin, timing.Status,
+			formatSeconds(timing.Seconds), formatSeconds(timing.EarliestStart),
+			formatSeconds(timing.LatestStart), formatSeconds(timing.Slack), critical)
+	}
+	fmt.Fprintln(w, "\nCOMPONENT\tPLUGINS\tDURATION\tCRITICAL PATH DURATION")
+	for _, component := range report.Components {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", component.Component, component.Plugins,
+			formatSeconds(component.Seconds), formatSeconds(component.CriticalSeconds))
+	}
+	w.Flush()
+	logger.ConsoleInfo.Printf("%s", strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// Report analyzes the durations of the plugins of the run result (i.e., the
+// json or yaml output of the run command), displays the critical path, the
+// slack of each plugin and the estimated wall time for the parallelism, and
+// returns the analysis. The critical path is also highlighted in the graph.
+func Report(resultFile string, reportOptions ReportOptions) (RunReport, error) {
+	logger.Debug.Printf("Entering Report(%s, %+v)...", resultFile, reportOptions)
+	defer logger.Debug.Println("Exiting Report")
+
+	result, err := getPluginsInfoFromJSONStrOrFile(resultFile)
+	if err != nil {
+		return RunReport{}, err
+	}
+	report, err := analyzeRun(result, reportOptions)
+	if err != nil {
+		return report, err
+	}
+	report.Result = resultFile
+	displayRunReport(report)
+
+	// NOTE: The analysis is complete even if the graph couldn't be generated.
+	dag, _ := getRunDAG(result)
+	pluginsInfo := getRunPlugins(result)
+	types := []string{}
+	for _, pInfo := range pluginsInfo {
+		if pType := getPluginType(pInfo.Name); !containsString(types, pType) {
+			types = append(types, pType)
+		}
+	}
+	for _, pType := range types {
+		typePlugins := Plugins{}
+		for _, pInfo := range pluginsInfo {
+			if getPluginType(pInfo.Name) == pType {
+				typePlugins = append(typePlugins, pInfo)
+			}
+		}
+		initGraph(pType, typePlugins)
+	}
+	if err = highlightCriticalPath(pluginsInfo, report.CriticalPath,
+		getCriticalEdges(dag, report.CriticalPath)); err != nil {
+		logger.Warning.Printf("Failed to highlight the critical path in the graph. Error: %s",
+			err.Error())
+		return report, nil
+	}
+	logger.ConsoleInfo.Printf("The critical path is highlighted in %s", getImagePath())
+	return report, nil
+}
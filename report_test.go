@@ -0,0 +1,200 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_analyzeRun(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ran := func(pInfo Plugin, startSeconds, seconds int) Plugin {
+		pInfo.Status = dStatusOk
+		pInfo.StartTime = start.Add(time.Duration(startSeconds) * time.Second)
+		pInfo.EndTime = pInfo.StartTime.Add(time.Duration(seconds) * time.Second)
+		return pInfo
+	}
+	tests := []struct {
+		name        string
+		result      RunStatus
+		parallelism int
+		want        RunReport
+		wantSlack   map[string]float64
+		wantEdges   [][2]string
+		wantErr     bool
+	}{
+		{
+			name: "Plugins of a type",
+			result: RunStatus{Plugins: Plugins{
+				ran(Plugin{Name: "X/a.pre"}, 0, 2),
+				ran(Plugin{Name: "X/b.pre", Requires: []string{"X/a.pre"}}, 2, 5),
+				ran(Plugin{Name: "Y/c.pre", RequiredBy: []string{"Y/d.pre"}}, 2, 1),
+				ran(Plugin{Name: "Y/d.pre", Requires: []string{"X/b.pre"}}, 7, 1),
+				{Name: "Y/e.pre", Status: dStatusSkip, Requires: []string{"X/a.pre"}},
+			}},
+			parallelism: 1,
+			want: RunReport{
+				Parallelism:         1,
+				ActualSeconds:       8,
+				SequentialSeconds:   9,
+				CriticalPathSeconds: 8,
+				EstimatedSeconds:    9,
+				CriticalPath:        []string{"X/a.pre", "X/b.pre", "Y/d.pre"},
+				Components: []ComponentTiming{
+					{Component: "X", Plugins: 2, Seconds: 7, CriticalSeconds: 7},
+					{Component: "Y", Plugins: 3, Seconds: 2, CriticalSeconds: 1},
+				},
+			},
+			wantSlack: map[string]float64{"X/a.pre": 0, "X/b.pre": 0, "Y/c.pre": 6,
+				"Y/d.pre": 0, "Y/e.pre": 6},
+			wantEdges: [][2]string{{"X/a.pre", "X/b.pre"}, {"X/b.pre", "Y/d.pre"}},
+		},
+		{
+			name: "Plugins of phases",
+			result: RunStatus{Phases: []RunStatus{
+				{Type: "pre", Plugins: Plugins{
+					ran(Plugin{Name: "X/a.pre"}, 0, 1),
+					ran(Plugin{Name: "X/b.pre"}, 0, 3),
+				}},
+				{Type: "post", Plugins: Plugins{
+					ran(Plugin{Name: "Y/c.post"}, 3, 2),
+					ran(Plugin{Name: "Y/d.post", Requires: []string{"Y/c.post"}}, 5, 1),
+				}},
+			}},
+			want: RunReport{
+				ActualSeconds:       6,
+				SequentialSeconds:   7,
+				CriticalPathSeconds: 6,
+				EstimatedSeconds:    6,
+				CriticalPath:        []string{"X/b.pre", "Y/c.post", "Y/d.post"},
+				Components: []ComponentTiming{
+					{Component: "X", Plugins: 2, Seconds: 4, CriticalSeconds: 3},
+					{Component: "Y", Plugins: 2, Seconds: 3, CriticalSeconds: 3},
+				},
+			},
+			wantSlack: map[string]float64{"X/a.pre": 2, "X/b.pre": 0, "Y/c.post": 0,
+				"Y/d.post": 0},
+			wantEdges: [][2]string{{"Y/c.post", "Y/d.post"}},
+		},
+		{
+			name: "Cyclic dependencies",
+			result: RunStatus{Plugins: Plugins{
+				{Name: "X/a.pre", Requires: []string{"X/b.pre"}},
+				{Name: "X/b.pre", Requires: []string{"X/a.pre"}},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzeRun(tt.result, ReportOptions{Parallelism: tt.parallelism})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("analyzeRun() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotSlack := map[string]float64{}
+			for _, timing := range got.Plugins {
+				gotSlack[timing.Plugin] = timing.Slack
+				if timing.Critical != (timing.Slack == 0 && timing.Seconds != 0) {
+					t.Errorf("analyzeRun() plugin %+v, want Critical only with no slack", timing)
+				}
+			}
+			if !reflect.DeepEqual(gotSlack, tt.wantSlack) {
+				t.Errorf("analyzeRun() slack = %v, want %v", gotSlack, tt.wantSlack)
+			}
+			got.Plugins = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("analyzeRun() = %+v, want %+v", got, tt.want)
+			}
+			dag, _ := getRunDAG(tt.result)
+			if edges := getCriticalEdges(dag, got.CriticalPath); !reflect.DeepEqual(edges, tt.wantEdges) {
+				t.Errorf("getCriticalEdges() = %v, want %v", edges, tt.wantEdges)
+			}
+		})
+	}
+}
+
+func Test_estimateWallTime(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	// INFO: "X/a.pre" is on the longest path, so it's run first even though
+	// 	"X/b.pre" and "X/c.pre" are ready as well.
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := RunStatus{Plugins: Plugins{}}
+	for _, p := range []struct {
+		name     string
+		seconds  int
+		requires []string
+	}{
+		{"X/a.pre", 1, nil},
+		{"X/b.pre", 2, nil},
+		{"X/c.pre", 2, nil},
+		{"X/d.pre", 4, []string{"X/a.pre"}},
+	} {
+		result.Plugins = append(result.Plugins, Plugin{Name: p.name, Requires: p.requires,
+			StartTime: start, EndTime: start.Add(time.Duration(p.seconds) * time.Second)})
+	}
+	tests := []struct {
+		parallelism int
+		want        float64
+	}{
+		{parallelism: 0, want: 5},
+		{parallelism: 1, want: 9},
+		{parallelism: 2, want: 5},
+		{parallelism: 3, want: 5},
+	}
+	for _, tt := range tests {
+		report, err := analyzeRun(result, ReportOptions{Parallelism: tt.parallelism})
+		if err != nil || report.EstimatedSeconds != tt.want {
+			t.Errorf("analyzeRun(parallelism: %d) = %v, %v, want %v",
+				tt.parallelism, report.EstimatedSeconds, err, tt.want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resultFile := filepath.Join(t.TempDir(), "result.json")
+	bytes, err := json.Marshal(RunStatus{Type: "pre", Status: dStatusOk, Plugins: Plugins{
+		{Name: "X/a.pre", Status: dStatusOk, StartTime: start, EndTime: start.Add(time.Second)},
+		{Name: "X/b.pre", Status: dStatusOk, Requires: []string{"X/a.pre"},
+			StartTime: start.Add(time.Second), EndTime: start.Add(3 * time.Second)},
+	}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err = os.WriteFile(resultFile, bytes, 0644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	report, err := Report(resultFile, ReportOptions{})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if report.Result != resultFile || report.CriticalPathSeconds != 3 ||
+		!reflect.DeepEqual(report.CriticalPath, []string{"X/a.pre", "X/b.pre"}) {
+		t.Errorf("Report() = %+v, want critical path X/a.pre -> X/b.pre of 3s", report)
+	}
+	if _, err = Report(filepath.Join(t.TempDir(), "missing.json"), ReportOptions{}); err == nil {
+		t.Errorf("Report() of a missing run result, want error")
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm select is used for selecting a subset of plugins to run or list.
+package pm
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// dReasonExcluded is the reason of the plugins skipped as they're not selected.
+const dReasonExcluded = "excluded"
+
+// SelectOptions are optional parameters to select a subset of plugins.
+type SelectOptions struct {
+	// Only selects the plugins whose names match any of the patterns. When
+	// 	not specified, all plugins are selected.
+	//  A pattern is either a glob pattern (Ex: "A/*"), or a regular
+	//  expression prefixed with "re:" (Ex: "re:^(A|B)/").
+	Only []string
+	// Exclude excludes the plugins whose names match any of the patterns.
+	Exclude []string
+	// Tags selects only the plugins having any of the tags. When specified
+	// 	along with Only, the plugins need to match both.
+	Tags []string
+	// ExcludeTags excludes the plugins having any of the tags.
+	ExcludeTags []string
+	// WithDependencies additionally selects the plugins required by the
+	// 	selected plugins, transitively.
+	WithDependencies bool
+	// WithDependents additionally selects the plugins that require the
+	// 	selected plugins, transitively.
+	WithDependents bool
+}
+
+// isSet returns whether any of the selection options are set.
+func (so SelectOptions) isSet() bool {
+	return len(so.Only) != 0 || len(so.Exclude) != 0 ||
+		len(so.Tags) != 0 || len(so.ExcludeTags) != 0
+}
+
+// hasAnyTag returns whether the plugin has any of the tags.
+func hasAnyTag(pInfo Plugin, tags []string) bool {
+	for _, tag := range pInfo.Tags {
+		if containsString(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginMatcher matches the plugin names against glob or regex patterns.
+type pluginMatcher struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// newPluginMatcher returns the matcher for the specified patterns.
+func newPluginMatcher(patterns []string) (pluginMatcher, error) {
+	matcher := pluginMatcher{}
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "re:") {
+			re, err := regexp.Compile(strings.TrimPrefix(pattern, "re:"))
+			if err != nil {
+				return matcher, logger.ConsoleError.PrintNReturnError(
+					"Invalid regular expression '%s'. Error: %s", pattern, err.Error())
+			}
+			matcher.regexps = append(matcher.regexps, re)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return matcher, logger.ConsoleError.PrintNReturnError(
+				"Invalid glob pattern '%s'. Error: %s", pattern, err.Error())
+		}
+		matcher.globs = append(matcher.globs, pattern)
+	}
+	return matcher, nil
+}
+
+// match returns whether the plugin name matches any of the patterns.
+func (matcher pluginMatcher) match(pName string) bool {
+	for _, glob := range matcher.globs {
+		if matched, _ := path.Match(glob, pName); matched {
+			return true
+		}
+	}
+	for _, re := range matcher.regexps {
+		if re.MatchString(pName) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTransitiveClosure returns the plugins reachable from the specified
+// plugins using the edges.
+func getTransitiveClosure(plugins map[string]bool, edges map[string][]string) map[string]bool {
+	closure := map[string]bool{}
+	queue := []string{}
+	for p := range plugins {
+		closure[p] = true
+		queue = append(queue, p)
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[p] {
+			if _, ok := edges[next]; ok && !closure[next] {
+				closure[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return closure
+}
+
+// selectPlugins returns the selected plugins and the excluded plugins.
+//
+//	NOTE: The selected plugins are in normalized form (i.e., only Requires
+//	are set), and the dependencies on the excluded plugins are replaced with
+//	the dependencies of the excluded plugins, so that the selected plugins
+//	still run in the same order. The excluded plugins are marked as skipped
+//	with "excluded" reason, and their dependencies are retained as is.
+func selectPlugins(pluginsInfo Plugins, selectOptions SelectOptions) (Plugins, Plugins, error) {
+	logger.Debug.Printf("Entering selectPlugins(%+v, %+v)...", pluginsInfo, selectOptions)
+	defer logger.Debug.Println("Exiting selectPlugins")
+
+	var selected, excluded Plugins
+	onlyMatcher, err := newPluginMatcher(selectOptions.Only)
+	if err != nil {
+		return selected, excluded, err
+	}
+	excludeMatcher, err := newPluginMatcher(selectOptions.Exclude)
+	if err != nil {
+		return selected, excluded, err
+	}
+
+	nPInfo := normalizePluginsInfo(pluginsInfo)
+	requires := map[string][]string{}
+	requiredBy := map[string][]string{}
+	pluginIndexes := map[string]int{}
+	for pIdx, pInfo := range nPInfo {
+		pluginIndexes[pInfo.Name] = pIdx
+		requires[pInfo.Name] = pInfo.Requires
+		if _, ok := requiredBy[pInfo.Name]; !ok {
+			requiredBy[pInfo.Name] = []string{}
+		}
+		for _, rs := range pInfo.Requires {
+			requiredBy[rs] = append(requiredBy[rs], pInfo.Name)
+		}
+	}
+
+	isSelected := map[string]bool{}
+	for _, pInfo := range nPInfo {
+		if (len(selectOptions.Only) == 0 || onlyMatcher.match(pInfo.Name)) &&
+			(len(selectOptions.Tags) == 0 || hasAnyTag(pInfo, selectOptions.Tags)) {
+			isSelected[pInfo.Name] = true
+		}
+	}
+	if len(selectOptions.Only) != 0 && len(isSelected) == 0 {
+		return selected, excluded, logger.ConsoleError.PrintNReturnError(
+			"No plugins match the specified patterns: %s", strings.Join(selectOptions.Only, ", "))
+	}
+	if len(selectOptions.Tags) != 0 && len(isSelected) == 0 {
+		return selected, excluded, logger.ConsoleError.PrintNReturnError(
+			"No plugins have the specified tags: %s", strings.Join(selectOptions.Tags, ", "))
+	}
+	closure := map[string]bool{}
+	if selectOptions.WithDependencies {
+		for p := range getTransitiveClosure(isSelected, requires) {
+			closure[p] = true
+		}
+	}
+	if selectOptions.WithDependents {
+		for p := range getTransitiveClosure(isSelected, requiredBy) {
+			closure[p] = true
+		}
+	}
+	for p := range closure {
+		isSelected[p] = true
+	}
+	for p := range isSelected {
+		if excludeMatcher.match(p) ||
+			hasAnyTag(nPInfo[pluginIndexes[p]], selectOptions.ExcludeTags) {
+			delete(isSelected, p)
+		}
+	}
+
+	// getSelectedRequires returns the selected plugins required by the
+	// 	plugin, by replacing the excluded plugins with their requires.
+	var getSelectedRequires func(p string, visited map[string]bool) []string
+	getSelectedRequires = func(p string, visited map[string]bool) []string {
+		selectedRequires := []string{}
+		for _, rs := range requires[p] {
+			if visited[rs] {
+				continue
+			}
+			visited[rs] = true
+			if _, ok := requires[rs]; isSelected[rs] || !ok {
+				// NOTE: Missing plugins are retained so that they're reported.
+				selectedRequires = append(selectedRequires, rs)
+				continue
+			}
+			selectedRequires = append(selectedRequires, getSelectedRequires(rs, visited)...)
+		}
+		return selectedRequires
+	}
+
+	for pIdx, pInfo := range nPInfo {
+		if !isSelected[pInfo.Name] {
+			pInfo = pluginsInfo[pIdx]
+			pInfo.Status = dStatusSkip
+			pInfo.Reason = dReasonExcluded
+			excluded = append(excluded, pInfo)
+			continue
+		}
+		pInfo.Requires = getSelectedRequires(pInfo.Name, map[string]bool{})
+		pInfo.RequiredBy = nil
+		selected = append(selected, pInfo)
+	}
+	logger.Info.Printf("Selected plugins: %+v, excluded plugins: %+v", selected, excluded)
+	return selected, excluded, nil
+}
+
+// mergeSelectedPlugins returns the run status of the selected plugins along
+// with the excluded plugins in the original order of the plugins, and with
+// their original dependencies.
+func mergeSelectedPlugins(pluginsInfo, selected, excluded Plugins) Plugins {
+	plugins := map[string]Plugin{}
+	for _, pInfo := range selected {
+		plugins[pInfo.Name] = pInfo
+	}
+	for _, pInfo := range excluded {
+		plugins[pInfo.Name] = pInfo
+	}
+	merged := Plugins{}
+	for _, pInfo := range pluginsInfo {
+		mergedPInfo := plugins[pInfo.Name]
+		mergedPInfo.Requires = pInfo.Requires
+		mergedPInfo.RequiredBy = pInfo.RequiredBy
+		merged = append(merged, mergedPInfo)
+	}
+	return merged
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_selectPlugins(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	// A <- B <- C <- D, and E is independent, where "A <- B" means B
+	// 	requires A.
+	pluginsInfo := Plugins{
+		{Name: "A/a.test", RequiredBy: []string{"B/b.test"}},
+		{Name: "B/b.test"},
+		{Name: "C/c.test", Requires: []string{"B/b.test"}},
+		{Name: "D/d.test", Requires: []string{"C/c.test"}},
+		{Name: "E/e.test"},
+	}
+	excludedPlugin := func(pIdx int) Plugin {
+		pInfo := pluginsInfo[pIdx]
+		pInfo.Status = dStatusSkip
+		pInfo.Reason = dReasonExcluded
+		return pInfo
+	}
+
+	tests := []struct {
+		name          string
+		selectOptions SelectOptions
+		wantSelected  Plugins
+		wantExcluded  Plugins
+		wantErr       bool
+	}{
+		{
+			name:          "Exclude plugin in the middle retains the order",
+			selectOptions: SelectOptions{Exclude: []string{"C/*"}},
+			wantSelected: Plugins{
+				{Name: "A/a.test", Requires: []string{}},
+				{Name: "B/b.test", Requires: []string{"A/a.test"}},
+				{Name: "D/d.test", Requires: []string{"B/b.test"}},
+				{Name: "E/e.test", Requires: []string{}},
+			},
+			wantExcluded: Plugins{excludedPlugin(2)},
+		},
+		{
+			name:          "Only with glob and regex patterns",
+			selectOptions: SelectOptions{Only: []string{"E/*", "re:^[AB]/"}},
+			wantSelected: Plugins{
+				{Name: "A/a.test", Requires: []string{}},
+				{Name: "B/b.test", Requires: []string{"A/a.test"}},
+				{Name: "E/e.test", Requires: []string{}},
+			},
+			wantExcluded: Plugins{excludedPlugin(2), excludedPlugin(3)},
+		},
+		{
+			name:          "Only with dependencies",
+			selectOptions: SelectOptions{Only: []string{"C/c.test"}, WithDependencies: true},
+			wantSelected: Plugins{
+				{Name: "A/a.test", Requires: []string{}},
+				{Name: "B/b.test", Requires: []string{"A/a.test"}},
+				{Name: "C/c.test", Requires: []string{"B/b.test"}},
+			},
+			wantExcluded: Plugins{excludedPlugin(3), excludedPlugin(4)},
+		},
+		{
+			name: "Only with dependents and exclude",
+			selectOptions: SelectOptions{Only: []string{"B/b.test"}, WithDependents: true,
+				Exclude: []string{"C/c.test"}},
+			wantSelected: Plugins{
+				{Name: "B/b.test", Requires: []string{}},
+				{Name: "D/d.test", Requires: []string{"B/b.test"}},
+			},
+			wantExcluded: Plugins{excludedPlugin(0), excludedPlugin(2), excludedPlugin(4)},
+		},
+		{
+			name:          "No plugins match",
+			selectOptions: SelectOptions{Only: []string{"X/*"}},
+			wantErr:       true,
+		},
+		{
+			name:          "Invalid regex",
+			selectOptions: SelectOptions{Exclude: []string{"re:("}},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, excluded, err := selectPlugins(pluginsInfo, tt.selectOptions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPlugins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(selected, tt.wantSelected) {
+				t.Errorf("selectPlugins() selected = %+v, want %+v", selected, tt.wantSelected)
+			}
+			if !reflect.DeepEqual(excluded, tt.wantExcluded) {
+				t.Errorf("selectPlugins() excluded = %+v, want %+v", excluded, tt.wantExcluded)
+			}
+			merged := mergeSelectedPlugins(pluginsInfo, selected, excluded)
+			for pIdx := range merged {
+				if merged[pIdx].Name != pluginsInfo[pIdx].Name ||
+					!reflect.DeepEqual(merged[pIdx].Requires, pluginsInfo[pIdx].Requires) ||
+					!reflect.DeepEqual(merged[pIdx].RequiredBy, pluginsInfo[pIdx].RequiredBy) {
+					t.Errorf("mergeSelectedPlugins() = %+v, want original order and dependencies", merged[pIdx])
+				}
+			}
+		})
+	}
+}
+
+func Test_selectPlugins_tags(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	// A <- B, and C is independent, where "A <- B" means B requires A.
+	pluginsInfo := Plugins{
+		{Name: "A/a.test", Tags: []string{"storage"}},
+		{Name: "B/b.test", Requires: []string{"A/a.test"}, Tags: []string{"network", "quick"}},
+		{Name: "C/c.test", Tags: []string{"storage", "full"}},
+	}
+
+	tests := []struct {
+		name          string
+		selectOptions SelectOptions
+		wantSelected  []string
+		wantErr       bool
+	}{
+		{
+			name:          "Select plugins having any of the tags",
+			selectOptions: SelectOptions{Tags: []string{"storage", "quick"}},
+			wantSelected:  []string{"A/a.test", "B/b.test", "C/c.test"},
+		},
+		{
+			name:          "Exclude tags take precedence",
+			selectOptions: SelectOptions{Tags: []string{"storage"}, ExcludeTags: []string{"full"}},
+			wantSelected:  []string{"A/a.test"},
+		},
+		{
+			name:          "Tags along with only and dependencies",
+			selectOptions: SelectOptions{Only: []string{"B/*", "C/*"}, Tags: []string{"network"}, WithDependencies: true},
+			wantSelected:  []string{"A/a.test", "B/b.test"},
+		},
+		{
+			name:          "No plugins have the tags",
+			selectOptions: SelectOptions{Tags: []string{"compute"}},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, excluded, err := selectPlugins(pluginsInfo, tt.selectOptions)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectPlugins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := []string{}
+			for _, pInfo := range selected {
+				got = append(got, pInfo.Name)
+			}
+			if !reflect.DeepEqual(got, tt.wantSelected) {
+				t.Errorf("selectPlugins() selected = %v, want %v", got, tt.wantSelected)
+			}
+			if len(selected)+len(excluded) != len(pluginsInfo) {
+				t.Errorf("selectPlugins() selected %d and excluded %d of %d plugins",
+					len(selected), len(excluded), len(pluginsInfo))
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm show is used for displaying the effective plugin info along with
+// the files where each value came from.
+package pm
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// PluginDetails is the effective plugin info after applying the drop-in files.
+type PluginDetails struct {
+	Plugin Plugin
+	// Files are the plugin file followed by the drop-in files applied over it.
+	Files []string
+	// Sources are the locations (i.e., "<file>:<line>") where each key was
+	// 	set.
+	Sources map[string][]string
+}
+
+// getPluginFile returns the file that defines the specified plugin in the
+// highest precedence library having the plugin, along with that library.
+func getPluginFile(libraries []string, pName string) (string, string, error) {
+	for _, library := range libraries {
+		files := []string{}
+		for _, format := range append([]string{""}, pluginDefFormats...) {
+			fi, err := os.Stat(filepath.FromSlash(library + pName + format))
+			if err == nil && !fi.IsDir() {
+				files = append(files, pName+format)
+			}
+		}
+		if len(files) > 1 {
+			return "", library, logger.ConsoleError.PrintNReturnError(
+				"Plugin %s is defined in both %s and %s.", pName, files[0], files[1])
+		}
+		if len(files) == 1 {
+			return files[0], library, nil
+		}
+	}
+	return "", "", logger.ConsoleError.PrintNReturnError(
+		"Plugin %s doesn't exist in %s plugins library.", pName, strings.Join(libraries, ", "))
+}
+
+// isPluginPresent returns whether the plugin is defined in any of the
+// libraries.
+func isPluginPresent(libraries []string, pName string) bool {
+	for _, library := range libraries {
+		for _, format := range append([]string{""}, pluginDefFormats...) {
+			fi, err := os.Stat(filepath.FromSlash(library + pName + format))
+			if err == nil && !fi.IsDir() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getPluginDetails returns the effective plugin info of the specified plugin
+// along with the locations where each key was set.
+//
+//	NOTE: An instance of a template plugin (Ex: "C/check@sda.precheck") is
+//	read from its template plugin file, unless the instance has its own
+//	plugin file.
+func getPluginDetails(libraries []string, overrideLibrary, pName string) (PluginDetails, error) {
+	details := PluginDetails{Sources: map[string][]string{}}
+	instance := ""
+	if tName := getTemplateName(pName); tName != "" && !isPluginPresent(libraries, pName) &&
+		isPluginPresent(libraries, tName) {
+		instance = getInstance(pName)
+	}
+	fileName := pName
+	if instance != "" {
+		fileName = getTemplateName(pName)
+	}
+	file, library, err := getPluginFile(libraries, fileName)
+	if err != nil {
+		return details, err
+	}
+	up := unitParser{sources: details.Sources}
+	details.Plugin, details.Files, err = loadPlugin(library, overrideLibrary, file, &up)
+	if err != nil {
+		return details, err
+	}
+	if instance != "" {
+		details.Plugin = getInstancePlugin(details.Plugin, instance)
+	}
+	if len(libraries) > 1 {
+		details.Plugin.Library = library
+	}
+	state, err := loadPluginsState()
+	pluginsInfo := Plugins{details.Plugin}
+	state.applyTo(pluginsInfo)
+	details.Plugin = pluginsInfo[0]
+	return details, err
+}
+
+// displayPluginDetails displays the effective plugin info in the key=value
+// format along with the locations where each key was set.
+func displayPluginDetails(details PluginDetails) {
+	pInfo := details.Plugin
+	if pInfo.Library != "" {
+		logger.ConsoleInfo.Printf("# Library: %s", pInfo.Library)
+	}
+	if pInfo.State != "" {
+		logger.ConsoleInfo.Printf("# State: %s", pInfo.State)
+	}
+	logger.ConsoleInfo.Printf("# %s", strings.Join(details.Files, "\n# "))
+	for _, kv := range [][2]string{
+		{"Description", pInfo.Description},
+		{"ExecStart", pInfo.ExecStart},
+		{"Requires", strings.Join(pInfo.Requires, " ")},
+		{"RequiredBy", strings.Join(pInfo.RequiredBy, " ")},
+		{"Tags", strings.Join(pInfo.Tags, " ")},
+		{"FailureMode", pInfo.FailureMode},
+		{"SkipOnIgnoredFailure", strconv.FormatBool(pInfo.SkipOnIgnoredFailure)},
+		{"TimeoutSec", strconv.Itoa(pInfo.TimeoutSec)},
+		{"Disabled", strconv.FormatBool(pInfo.Disabled)},
+		{"Instances", strings.Join(pInfo.Instances, " ")},
+		{"InstancesGlob", pInfo.InstancesGlob},
+		{"InstancesFrom", pInfo.InstancesFrom},
+	} {
+		sources, ok := details.Sources[kv[0]]
+		if !ok {
+			continue
+		}
+		logger.ConsoleInfo.Printf("%s=%s\t(%s)", kv[0], kv[1], strings.Join(sources, ", "))
+	}
+}
+
+// Show displays the effective plugin info of the specified plugins after
+// applying their drop-in files, and the files where each value came from.
+func Show(libraries []string, pluginNames []string) ([]PluginDetails, error) {
+	logger.Debug.Printf("Entering Show(%v, %v)...", libraries, pluginNames)
+	defer logger.Debug.Println("Exiting Show")
+
+	pluginsDetails := []PluginDetails{}
+	if len(pluginNames) == 0 {
+		return pluginsDetails, logger.ConsoleError.PrintNReturnError(
+			"No plugins specified. Specify the plugins as <component>/<plugin-file>.")
+	}
+	for idx, pName := range pluginNames {
+		details, err := getPluginDetails(libraries, config.GetPluginsOverrideLibrary(), pName)
+		if err != nil {
+			return pluginsDetails, err
+		}
+		if idx != 0 {
+			logger.ConsoleInfo.Printf("")
+		}
+		displayPluginDetails(details)
+		pluginsDetails = append(pluginsDetails, details)
+	}
+	return pluginsDetails, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_getPluginDetails(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.test":             "Description=A\nRequires=B/b.test\nExecStart=/bin/echo A\n",
+		"A/a.test.d/10-a.conf": "[Unit]\nRequires=C/c.test\n[Exec]\nExecStart=\n",
+		"B/b.test.json":        `{"Description": "B", "RequiredBy": ["C/c.test"]}`,
+		"B/b.test.d/10-b.conf": "RequiredBy=\n",
+		"C/c.test":             "Description=C\n",
+		"C/c.test.json":        `{"Description": "C"}`,
+		"D/d.test.d/10-d.conf": "Description=D\n",
+		"E/e@.test":            "Description=Checking %i\nExecStart=/bin/echo %i\n[Install]\nInstances=sda\n",
+	})
+	overrideLibrary := createTestLibrary(t, map[string]string{
+		"A/a.test.d/20-a.conf": "FailureMode=warn\n",
+	})
+
+	tests := []struct {
+		name    string
+		pName   string
+		want    PluginDetails
+		wantErr string
+	}{
+		{
+			name:  "Plugin with library and override drop-ins",
+			pName: "A/a.test",
+			want: PluginDetails{
+				Plugin: Plugin{Name: "A/a.test", Description: "A",
+					Requires: []string{"B/b.test", "C/c.test"}, FailureMode: failureModeWarn},
+				Files: []string{"A/a.test", "A/a.test.d/10-a.conf", overrideLibrary + "A/a.test.d/20-a.conf"},
+				Sources: map[string][]string{
+					"Description": {"A/a.test:1"},
+					"Requires":    {"A/a.test:2", "A/a.test.d/10-a.conf:2"},
+					"ExecStart":   {"A/a.test.d/10-a.conf:4"},
+					"FailureMode": {overrideLibrary + "A/a.test.d/20-a.conf:1"},
+				},
+			},
+		},
+		{
+			name:  "Plugin definition file with a drop-in resetting a list",
+			pName: "B/b.test",
+			want: PluginDetails{
+				Plugin: Plugin{Name: "B/b.test", Description: "B"},
+				Files:  []string{"B/b.test.json", "B/b.test.d/10-b.conf"},
+				Sources: map[string][]string{
+					"Description": {"B/b.test.json"},
+					"RequiredBy":  {"B/b.test.d/10-b.conf:1"},
+				},
+			},
+		},
+		{
+			name:  "Instance of a template plugin",
+			pName: "E/e@sdb.test",
+			want: PluginDetails{
+				Plugin: Plugin{Name: "E/e@sdb.test", Description: "Checking sdb", ExecStart: "/bin/echo sdb",
+					Requires: []string{}, RequiredBy: []string{}},
+				Files: []string{"E/e@.test"},
+				Sources: map[string][]string{
+					"Description": {"E/e@.test:1"},
+					"ExecStart":   {"E/e@.test:2"},
+					"Instances":   {"E/e@.test:4"},
+				},
+			},
+		},
+		{
+			name:    "Plugin defined in multiple formats",
+			pName:   "C/c.test",
+			wantErr: "Plugin C/c.test is defined in both C/c.test and C/c.test.json.",
+		},
+		{
+			name:    "Drop-in without the plugin",
+			pName:   "D/d.test",
+			wantErr: "Plugin D/d.test doesn't exist in " + library + " plugins library.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPluginDetails([]string{library}, overrideLibrary, tt.pName)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("getPluginDetails() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getPluginDetails() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPluginDetails() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm state is used for persistently disabling and masking plugins
+// without deleting the plugin files.
+package pm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Plugin states
+const (
+	// dStateDisabled plugins are treated as succeeded without being run, so
+	// 	their dependents still run.
+	dStateDisabled = "disabled"
+	// dStateMasked plugins are skipped along with their dependents.
+	dStateMasked = "masked"
+)
+
+// Plugin state commands
+const (
+	dStateCmdDisable = "disable"
+	dStateCmdEnable  = "enable"
+	dStateCmdMask    = "mask"
+	dStateCmdUnmask  = "unmask"
+)
+
+// stateCmdMsgs are the messages displayed after running the state commands.
+var stateCmdMsgs = map[string]string{
+	dStateCmdDisable: "Disabled",
+	dStateCmdEnable:  "Enabled",
+	dStateCmdMask:    "Masked",
+	dStateCmdUnmask:  "Unmasked",
+}
+
+// pluginsStateFileName is the file in the state dir that stores the state
+// of the plugins.
+const pluginsStateFileName = "plugins-state.yaml"
+
+// PluginsState is the persistent state of the plugins.
+type PluginsState struct {
+	Disabled []string `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Masked   []string `yaml:"masked,omitempty" json:"masked,omitempty"`
+}
+
+// getPluginsStateFile returns the path of the plugins state file.
+func getPluginsStateFile() string {
+	return config.GetStateDir() + pluginsStateFileName
+}
+
+// loadPluginsState reads the plugins state file.
+//
+//	NOTE: A missing state file is treated as no plugins being disabled or
+//	masked.
+func loadPluginsState() (PluginsState, error) {
+	var state PluginsState
+	stateFile := getPluginsStateFile()
+	bytes, err := os.ReadFile(filepath.Clean(stateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, logger.ConsoleError.PrintNReturnError(
+			"Failed to read plugins state file %s. Error: %s", stateFile, err.Error())
+	}
+	if err = yaml.Unmarshal(bytes, &state); err != nil {
+		return state, logger.ConsoleError.PrintNReturnError(
+			"Plugins state file %s is not in expected format. Error: %s",
+			stateFile, err.Error())
+	}
+	return state, nil
+}
+
+// savePluginsState writes the plugins state file.
+func savePluginsState(state PluginsState) error {
+	stateFile := getPluginsStateFile()
+	if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
+		return logger.ConsoleError.PrintNReturnError(
+			"Failed to create state dir %s. Error: %s", filepath.Dir(stateFile), err.Error())
+	}
+	sort.Strings(state.Disabled)
+	sort.Strings(state.Masked)
+	bytes, err := yaml.Marshal(state)
+	if err != nil {
+		return logger.ConsoleError.PrintNReturnError(
+			"Failed to marshal plugins state. Error: %s", err.Error())
+	}
+	// INFO: Write to a temporary file and rename it, so that a concurrent
+	// 	run never reads a partially written state file.
+	tmpFile := stateFile + ".tmp"
+	if err = os.WriteFile(tmpFile, bytes, 0644); err != nil {
+		return logger.ConsoleError.PrintNReturnError(
+			"Failed to write plugins state file %s. Error: %s", tmpFile, err.Error())
+	}
+	if err = os.Rename(tmpFile, stateFile); err != nil {
+		return logger.ConsoleError.PrintNReturnError(
+			"Failed to write plugins state file %s. Error: %s", stateFile, err.Error())
+	}
+	return nil
+}
+
+// getPluginStateDir returns the directory in the state dir, where the plugin
+// could persist its state across the runs. It's exposed to the plugin as
+// PM_STATE_DIR env value.
+func getPluginStateDir(pName string) string {
+	return config.GetStateDir() + filepath.FromSlash("plugins/"+pName) + string(os.PathSeparator)
+}
+
+// getState returns the state of the specified plugin.
+//
+//	NOTE: Masking takes precedence over disabling.
+func (state PluginsState) getState(pName string) string {
+	if containsString(state.Masked, pName) {
+		return dStateMasked
+	}
+	if containsString(state.Disabled, pName) {
+		return dStateDisabled
+	}
+	return ""
+}
+
+// applyTo sets the state of the specified plugins as per the persistent
+// state, overriding any existing state of the plugins.
+//
+//	NOTE: Disabling or masking a template plugin applies to all its instances.
+func (state PluginsState) applyTo(pluginsInfo Plugins) {
+	for pIdx, pInfo := range pluginsInfo {
+		pluginsInfo[pIdx].State = state.getState(pInfo.Name)
+		if pluginsInfo[pIdx].State == "" {
+			pluginsInfo[pIdx].State = state.getState(getTemplateName(pInfo.Name))
+		}
+	}
+}
+
+// removeString returns the list without the specified string.
+func removeString(list []string, str string) []string {
+	newList := []string{}
+	for _, item := range list {
+		if item != str {
+			newList = append(newList, item)
+		}
+	}
+	return newList
+}
+
+// ChangePluginsState disables, enables, masks or unmasks the specified
+// plugins based on the state command.
+func ChangePluginsState(libraries []string, stateCmd string, pluginNames []string) error {
+	logger.Debug.Printf("Entering ChangePluginsState(%v, %s, %v)...",
+		libraries, stateCmd, pluginNames)
+	defer logger.Debug.Println("Exiting ChangePluginsState")
+
+	if len(pluginNames) == 0 {
+		return logger.ConsoleError.PrintNReturnError(
+			"No plugins specified. Specify the plugins as <component>/<plugin-file>.")
+	}
+	state, err := loadPluginsState()
+	if err != nil {
+		return err
+	}
+	for _, pName := range pluginNames {
+		switch stateCmd {
+		case dStateCmdDisable, dStateCmdMask:
+			// INFO: Only the existing plugins could be disabled or masked, while
+			// 	the plugins whose files were removed could still be enabled or
+			// 	unmasked. An instance of a template plugin exists when the
+			// 	template plugin exists.
+			if tName := getTemplateName(pName); tName != "" && isPluginPresent(libraries, tName) {
+				logger.Info.Printf("Plugin %s is an instance of %s plugin.", pName, tName)
+			} else if _, _, err = getPluginFile(libraries, pName); err != nil {
+				return err
+			}
+			if stateCmd == dStateCmdDisable {
+				if !containsString(state.Disabled, pName) {
+					state.Disabled = append(state.Disabled, pName)
+				}
+			} else if !containsString(state.Masked, pName) {
+				state.Masked = append(state.Masked, pName)
+			}
+		case dStateCmdEnable:
+			state.Disabled = removeString(state.Disabled, pName)
+		case dStateCmdUnmask:
+			state.Masked = removeString(state.Masked, pName)
+		default:
+			return logger.ConsoleError.PrintNReturnError(
+				"Unknown plugin state command %s.", stateCmd)
+		}
+	}
+	if err = savePluginsState(state); err != nil {
+		return err
+	}
+	for _, pName := range pluginNames {
+		logger.ConsoleInfo.Printf("%s: %s", pName, stateCmdMsgs[stateCmd])
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func TestChangePluginsState(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.test": "Description=A\n",
+		"B/b.test": "Description=B\nRequires=A/a.test\n",
+		"C/c.test": "Description=C\n",
+	})
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+
+	tests := []struct {
+		name        string
+		stateCmd    string
+		pluginNames []string
+		want        PluginsState
+		wantErr     bool
+	}{
+		{
+			name:        "Disable plugins",
+			stateCmd:    dStateCmdDisable,
+			pluginNames: []string{"C/c.test", "A/a.test"},
+			want:        PluginsState{Disabled: []string{"A/a.test", "C/c.test"}},
+		},
+		{
+			name:        "Mask a plugin",
+			stateCmd:    dStateCmdMask,
+			pluginNames: []string{"B/b.test"},
+			want: PluginsState{Disabled: []string{"A/a.test", "C/c.test"},
+				Masked: []string{"B/b.test"}},
+		},
+		{
+			name:        "Disable a plugin that doesn't exist",
+			stateCmd:    dStateCmdDisable,
+			pluginNames: []string{"D/d.test"},
+			want: PluginsState{Disabled: []string{"A/a.test", "C/c.test"},
+				Masked: []string{"B/b.test"}},
+			wantErr: true,
+		},
+		{
+			name:        "Enable a plugin",
+			stateCmd:    dStateCmdEnable,
+			pluginNames: []string{"A/a.test"},
+			want: PluginsState{Disabled: []string{"C/c.test"},
+				Masked: []string{"B/b.test"}},
+		},
+		{
+			name:        "Unmask a plugin",
+			stateCmd:    dStateCmdUnmask,
+			pluginNames: []string{"B/b.test"},
+			want:        PluginsState{Disabled: []string{"C/c.test"}},
+		},
+		{
+			name:     "No plugins specified",
+			stateCmd: dStateCmdMask,
+			want:     PluginsState{Disabled: []string{"C/c.test"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ChangePluginsState([]string{library}, tt.stateCmd, tt.pluginNames)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ChangePluginsState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, err := loadPluginsState()
+			if err != nil {
+				t.Fatalf("loadPluginsState() error = %v", err)
+			}
+			if len(got.Disabled) == 0 {
+				got.Disabled = nil
+			}
+			if len(got.Masked) == 0 {
+				got.Masked = nil
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangePluginsState() state = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// The plugins info read from the library reflects the persistent state.
+	if err := ChangePluginsState([]string{library}, dStateCmdMask, []string{"C/c.test"}); err != nil {
+		t.Fatalf("ChangePluginsState() error = %v", err)
+	}
+	pluginsInfo, err := getPluginsInfoFromLibrary("test", library)
+	if err != nil {
+		t.Fatalf("getPluginsInfoFromLibrary() error = %v", err)
+	}
+	states := map[string]string{}
+	for _, pInfo := range pluginsInfo {
+		states[pInfo.Name] = pInfo.State
+	}
+	wantStates := map[string]string{"A/a.test": "", "B/b.test": "", "C/c.test": dStateMasked}
+	if !reflect.DeepEqual(states, wantStates) {
+		t.Errorf("getPluginsInfoFromLibrary() states = %+v, want %+v", states, wantStates)
+	}
+
+	// Validation reports the dependencies on masked plugins.
+	if err = ChangePluginsState([]string{library}, dStateCmdMask, []string{"A/a.test"}); err != nil {
+		t.Fatalf("ChangePluginsState() error = %v", err)
+	}
+	report, err := ValidateLibrary(library, ValidateOptions{})
+	if err != nil {
+		t.Fatalf("ValidateLibrary() error = %v", err)
+	}
+	wantIssues := []ValidationIssue{{Type: "test", File: "B/b.test",
+		Message: "Dependency on masked plugin 'A/a.test'; the plugin would be skipped."}}
+	if !reflect.DeepEqual(report.Issues, wantIssues) {
+		t.Errorf("ValidateLibrary() Issues = %+v, want %+v", report.Issues, wantIssues)
+	}
+}
+
+func TestRunFromJSONStrOrFile_state(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	defer config.SetStateDir(config.GetStateDir())
+	config.SetStateDir(t.TempDir())
+	if err := savePluginsState(PluginsState{Disabled: []string{"A/a.test"}}); err != nil {
+		t.Fatalf("savePluginsState() error = %v", err)
+	}
+
+	// INFO: The result of a run in which B was masked, while A was not
+	// 	disabled.
+	previous := RunStatus{Type: "test", Plugins: Plugins{
+		{Name: "A/a.test", Description: "A", ExecStart: "exit 1", Status: dStatusFail},
+		{Name: "B/b.test", Description: "B", ExecStart: "/bin/true", State: dStateMasked,
+			Status: dStatusSkip, Reason: dStateMasked},
+	}}
+	bytes, err := json.Marshal(previous)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	rerun := RunStatus{}
+	if err = RunFromJSONStrOrFile(&rerun, string(bytes), RunOptions{}); err != nil {
+		t.Fatalf("RunFromJSONStrOrFile() error = %v", err)
+	}
+	want := map[string][]string{
+		"A/a.test": {dStateDisabled, dStatusOk, dStateDisabled},
+		"B/b.test": {"", dStatusOk, ""},
+	}
+	for _, pInfo := range rerun.Plugins {
+		if got := []string{pInfo.State, pInfo.Status, pInfo.Reason}; !reflect.DeepEqual(got, want[pInfo.Name]) {
+			t.Errorf("RunFromJSONStrOrFile() plugin %s (State, Status, Reason) = %v, want %v",
+				pInfo.Name, got, want[pInfo.Name])
+		}
+	}
+}
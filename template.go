@@ -0,0 +1,210 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm template is used for instantiating the template plugins i.e.,
+// "<component>/<name>@.<type>" once per instance (Ex: per disk or per NIC).
+package pm
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	osutils "github.com/VeritasOS/plugin-manager/utils/os"
+)
+
+// instanceSpecifier is substituted with the instance in the Description,
+// ExecStart, Requires and RequiredBy of the template plugins.
+const instanceSpecifier = "%i"
+
+// isPluginTemplate returns whether the plugin is a template plugin i.e.,
+// "<component>/<name>@.<type>".
+func isPluginTemplate(pName string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(pName, path.Ext(pName)), "@")
+}
+
+// getInstanceName returns the name of the instance of the template plugin
+// i.e., "<component>/<name>@<instance>.<type>".
+func getInstanceName(template, instance string) string {
+	ext := path.Ext(template)
+	return strings.TrimSuffix(template, ext) + instance + ext
+}
+
+// getTemplateName returns the template plugin name of the instance plugin,
+// or an empty string when the plugin is not an instance plugin.
+func getTemplateName(pName string) string {
+	ext := path.Ext(pName)
+	base := strings.TrimSuffix(pName, ext)
+	idx := strings.LastIndex(base, "@")
+	if idx == -1 || idx == len(base)-1 || strings.Contains(base[idx:], "/") {
+		return ""
+	}
+	return base[:idx+1] + ext
+}
+
+// getInstance returns the instance of the instance plugin, or an empty string
+// when the plugin is not an instance plugin.
+func getInstance(pName string) string {
+	tName := getTemplateName(pName)
+	if tName == "" {
+		return ""
+	}
+	ext := path.Ext(pName)
+	return strings.TrimSuffix(strings.TrimPrefix(pName, strings.TrimSuffix(tName, ext)), ext)
+}
+
+// getInstancePlugin returns the instance plugin of the template plugin, by
+// substituting the instance in its Description, ExecStart, Requires and
+// RequiredBy.
+func getInstancePlugin(pInfo Plugin, instance string) Plugin {
+	iInfo := pInfo
+	iInfo.Name = getInstanceName(pInfo.Name, instance)
+	iInfo.Description = strings.Replace(pInfo.Description, instanceSpecifier, instance, -1)
+	iInfo.ExecStart = strings.Replace(pInfo.ExecStart, instanceSpecifier, instance, -1)
+	iInfo.Requires = []string{}
+	for _, rs := range pInfo.Requires {
+		iInfo.Requires = append(iInfo.Requires, strings.Replace(rs, instanceSpecifier, instance, -1))
+	}
+	iInfo.RequiredBy = []string{}
+	for _, rby := range pInfo.RequiredBy {
+		iInfo.RequiredBy = append(iInfo.RequiredBy, strings.Replace(rby, instanceSpecifier, instance, -1))
+	}
+	iInfo.Instances = nil
+	iInfo.InstancesGlob = ""
+	iInfo.InstancesFrom = ""
+	return iInfo
+}
+
+// getEmptyTemplateDependents returns the plugins requiring the template
+// plugins that don't have any instances, along with those template plugins.
+// Such dependencies are dropped while expanding the template plugins, and
+// hence don't constrain the plugins requiring them.
+func getEmptyTemplateDependents(pluginsInfo Plugins, templateInstances map[string][]string) map[string][]string {
+	dependents := map[string][]string{}
+	addDependent := func(dependent, template string) {
+		if instances, ok := templateInstances[template]; ok && len(instances) == 0 &&
+			!containsString(dependents[dependent], template) {
+			dependents[dependent] = append(dependents[dependent], template)
+		}
+	}
+	for _, pInfo := range pluginsInfo {
+		for _, rs := range pInfo.Requires {
+			addDependent(pInfo.Name, rs)
+		}
+		for _, rby := range pInfo.RequiredBy {
+			addDependent(rby, pInfo.Name)
+		}
+	}
+	return dependents
+}
+
+// getTemplateInstances returns the instances of the template plugin from its
+// Instances, the base names of the paths matching its InstancesGlob, and the
+// output lines of its InstancesFrom command.
+func getTemplateInstances(pInfo Plugin, library string) ([]string, error) {
+	envMap := osutils.EnvMap()
+	envMap["PM_LIBRARY"] = library
+	getEnvVal := func(name string) string {
+		return envMap[name]
+	}
+
+	instances := append([]string{}, pInfo.Instances...)
+	if pInfo.InstancesGlob != "" {
+		matches, err := filepath.Glob(os.Expand(pInfo.InstancesGlob, getEnvVal))
+		if err != nil {
+			return instances, logger.ConsoleError.PrintNReturnError(
+				"Invalid InstancesGlob '%s' of %s plugin. Error: %s",
+				pInfo.InstancesGlob, pInfo.Name, err.Error())
+		}
+		for _, match := range matches {
+			instances = append(instances, filepath.Base(match))
+		}
+	}
+	if pInfo.InstancesFrom != "" {
+		cmdParam := strings.Split(os.Expand(pInfo.InstancesFrom, getEnvVal), " ")
+		cmd := exec.Command(cmdParam[0], cmdParam[1:]...)
+		out, err := cmd.Output()
+		if err != nil {
+			return instances, logger.ConsoleError.PrintNReturnError(
+				"Failed to get the instances of %s plugin using '%s'. Error: %s",
+				pInfo.Name, pInfo.InstancesFrom, err.Error())
+		}
+		instances = append(instances, strings.Fields(string(out))...)
+	}
+
+	uniqueInstances := []string{}
+	for _, instance := range instances {
+		if strings.ContainsAny(instance, "/@") {
+			return instances, logger.ConsoleError.PrintNReturnError(
+				"Invalid instance '%s' of %s plugin.", instance, pInfo.Name)
+		}
+		if !containsString(uniqueInstances, instance) {
+			uniqueInstances = append(uniqueInstances, instance)
+		}
+	}
+	return uniqueInstances, nil
+}
+
+// expandPluginTemplates replaces the template plugins with their instance
+// plugins. The dependencies on a template plugin are replaced with the
+// dependencies on all its instances, while an instance could be depended on
+// using its name (Ex: "C/check@sda.precheck").
+func expandPluginTemplates(pluginsInfo Plugins, library string) (Plugins, error) {
+	expanded := Plugins{}
+	templateInstances := map[string][]string{}
+	for _, pInfo := range pluginsInfo {
+		if !isPluginTemplate(pInfo.Name) {
+			expanded = append(expanded, pInfo)
+			continue
+		}
+		instances, err := getTemplateInstances(pInfo, library)
+		if err != nil {
+			return expanded, err
+		}
+		logger.Info.Printf("Plugin %s instances: %v", pInfo.Name, instances)
+		templateInstances[pInfo.Name] = []string{}
+		for _, instance := range instances {
+			iInfo := getInstancePlugin(pInfo, instance)
+			expanded = append(expanded, iInfo)
+			templateInstances[pInfo.Name] = append(templateInstances[pInfo.Name], iInfo.Name)
+		}
+	}
+	if len(templateInstances) == 0 {
+		return expanded, nil
+	}
+	// INFO: The plugins requiring a template plugin without any instances
+	// 	run as if they don't require it, so warn about such dependencies.
+	emptyTemplateDependents := getEmptyTemplateDependents(pluginsInfo, templateInstances)
+	dependents := []string{}
+	for dependent := range emptyTemplateDependents {
+		dependents = append(dependents, dependent)
+	}
+	sort.Strings(dependents)
+	for _, dependent := range dependents {
+		logger.ConsoleWarning.Printf("Plugin %s requires %s, which has no instances; ignoring the dependency.",
+			dependent, strings.Join(emptyTemplateDependents[dependent], ", "))
+	}
+
+	expandDependencies := func(dependencies []string) []string {
+		if len(dependencies) == 0 {
+			return dependencies
+		}
+		deps := []string{}
+		for _, dep := range dependencies {
+			if instances, ok := templateInstances[dep]; ok {
+				deps = append(deps, instances...)
+				continue
+			}
+			deps = append(deps, dep)
+		}
+		return deps
+	}
+	for pIdx := range expanded {
+		expanded[pIdx].Requires = expandDependencies(expanded[pIdx].Requires)
+		expanded[pIdx].RequiredBy = expandDependencies(expanded[pIdx].RequiredBy)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VeritasOS/plugin-manager/config"
+)
+
+func Test_getTemplateName(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		pName        string
+		wantTemplate bool
+		want         string
+		wantInstance string
+	}{
+		{pName: "C/check@.precheck", wantTemplate: true, want: ""},
+		{pName: "C/check@sda.precheck", want: "C/check@.precheck", wantInstance: "sda"},
+		{pName: "C/check@%i.precheck", want: "C/check@.precheck", wantInstance: "%i"},
+		{pName: "C/check.precheck", want: ""},
+		{pName: "C@x/check.precheck", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pName, func(t *testing.T) {
+			if got := isPluginTemplate(tt.pName); got != tt.wantTemplate {
+				t.Errorf("isPluginTemplate() = %v, want %v", got, tt.wantTemplate)
+			}
+			if got := getTemplateName(tt.pName); got != tt.want {
+				t.Errorf("getTemplateName() = %v, want %v", got, tt.want)
+			}
+			if got := getInstance(tt.pName); got != tt.wantInstance {
+				t.Errorf("getInstance() = %v, want %v", got, tt.wantInstance)
+			}
+		})
+	}
+	if got := getInstanceName("C/check@.precheck", "sda"); got != "C/check@sda.precheck" {
+		t.Errorf("getInstanceName() = %v, want C/check@sda.precheck", got)
+	}
+}
+
+func Test_expandPluginTemplates(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	globDir := t.TempDir()
+	for _, dev := range []string{"sdc", "sdd"} {
+		if err := os.WriteFile(filepath.Join(globDir, dev), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name        string
+		pluginsInfo Plugins
+		want        Plugins
+		wantErr     bool
+	}{
+		{
+			name: "Instances from the list, glob and command",
+			pluginsInfo: Plugins{
+				{
+					Name:          "C/check@.test",
+					Description:   "Checking %i",
+					ExecStart:     "/bin/echo /dev/%i",
+					Instances:     []string{"sda", "sdb"},
+					InstancesGlob: filepath.Join(globDir, "sd*"),
+					InstancesFrom: "/bin/echo sdb sde",
+				},
+			},
+			want: Plugins{
+				{Name: "C/check@sda.test", Description: "Checking sda", ExecStart: "/bin/echo /dev/sda",
+					Requires: []string{}, RequiredBy: []string{}},
+				{Name: "C/check@sdb.test", Description: "Checking sdb", ExecStart: "/bin/echo /dev/sdb",
+					Requires: []string{}, RequiredBy: []string{}},
+				{Name: "C/check@sdc.test", Description: "Checking sdc", ExecStart: "/bin/echo /dev/sdc",
+					Requires: []string{}, RequiredBy: []string{}},
+				{Name: "C/check@sdd.test", Description: "Checking sdd", ExecStart: "/bin/echo /dev/sdd",
+					Requires: []string{}, RequiredBy: []string{}},
+				{Name: "C/check@sde.test", Description: "Checking sde", ExecStart: "/bin/echo /dev/sde",
+					Requires: []string{}, RequiredBy: []string{}},
+			},
+		},
+		{
+			name: "Dependencies on all instances and on an instance",
+			pluginsInfo: Plugins{
+				{Name: "A/a@.test", Instances: []string{"x", "y"}},
+				{Name: "B/b@.test", Instances: []string{"x"}, Requires: []string{"A/a@%i.test"}},
+				{Name: "C/c.test", Requires: []string{"A/a@.test"}, RequiredBy: []string{"B/b@x.test"}},
+			},
+			want: Plugins{
+				{Name: "A/a@x.test", Requires: []string{}, RequiredBy: []string{}},
+				{Name: "A/a@y.test", Requires: []string{}, RequiredBy: []string{}},
+				{Name: "B/b@x.test", Requires: []string{"A/a@x.test"}, RequiredBy: []string{}},
+				{Name: "C/c.test", Requires: []string{"A/a@x.test", "A/a@y.test"},
+					RequiredBy: []string{"B/b@x.test"}},
+			},
+		},
+		{
+			name: "Invalid instance",
+			pluginsInfo: Plugins{
+				{Name: "A/a@.test", Instances: []string{"x/y"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failing instances command",
+			pluginsInfo: Plugins{
+				{Name: "A/a@.test", InstancesFrom: "/bin/false"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPluginTemplates(tt.pluginsInfo, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandPluginTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandPluginTemplates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getEmptyTemplateDependents(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	pluginsInfo := Plugins{
+		{Name: "A/a@.test", RequiredBy: []string{"D/d.test"}},
+		{Name: "B/b@.test", Instances: []string{"x"}},
+		{Name: "C/c.test", Requires: []string{"A/a@.test", "B/b@.test"}},
+		{Name: "D/d.test", Requires: []string{"A/a@.test"}},
+	}
+	templateInstances := map[string][]string{"A/a@.test": {}, "B/b@.test": {"B/b@x.test"}}
+	want := map[string][]string{"C/c.test": {"A/a@.test"}, "D/d.test": {"A/a@.test"}}
+	if got := getEmptyTemplateDependents(pluginsInfo, templateInstances); !reflect.DeepEqual(got, want) {
+		t.Errorf("getEmptyTemplateDependents() = %v, want %v", got, want)
+	}
+}
+
+func TestRunFromLibrary_templates(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a@.test": "Description=A %i\nExecStart=/bin/echo %i\n[Install]\nInstances=x y\n",
+		"B/b.test":  "Description=B\nExecStart=/bin/echo B\nRequires=A/a@.test\n",
+		"C/c.test":  "Description=C\nExecStart=/bin/echo C\nRequires=A/a@y.test\n",
+	})
+	stateDir := config.GetStateDir()
+	defer config.SetStateDir(stateDir)
+	config.SetStateDir(t.TempDir())
+	if err := ChangePluginsState([]string{library}, dStateCmdMask, []string{"A/a@y.test"}); err != nil {
+		t.Fatalf("ChangePluginsState() error = %v", err)
+	}
+
+	result := RunStatus{}
+	if err := RunFromLibrary(&result, "test", RunOptions{Library: library}); err != nil {
+		t.Fatalf("RunFromLibrary() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, pInfo := range result.Plugins {
+		got[pInfo.Name] = pInfo.Status
+	}
+	want := map[string]string{
+		"A/a@x.test": dStatusOk,
+		"A/a@y.test": dStatusSkip,
+		"B/b.test":   dStatusSkip,
+		"C/c.test":   dStatusSkip,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RunFromLibrary() Plugins = %+v, want %+v", got, want)
+	}
+	for _, pInfo := range result.Plugins {
+		if pInfo.Name == "A/a@x.test" && (pInfo.Description != "A x" || strings.Join(pInfo.StdOutErr, "") != "x") {
+			t.Errorf("Plugin %s Description = %s, StdOutErr = %v, want instance substituted",
+				pInfo.Name, pInfo.Description, pInfo.StdOutErr)
+		}
+	}
+}
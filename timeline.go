@@ -0,0 +1,236 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm timeline is used for generating the timeline image of a run,
+// having a bar per plugin from its start to its end.
+package pm
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// Dimensions of the timeline image in pixels.
+const (
+	timelineChartWidth = 800
+	timelineRowHeight  = 20
+	timelineMargin     = 10
+	// timelineCharWidth is the approximate width of a character of the
+	// 	labels, which is used for sizing the labels column.
+	timelineCharWidth = 7
+)
+
+// getTimelinePath returns the path of the timeline image of the current run,
+// which is next to the graph image.
+func getTimelinePath() string {
+	return config.GetPMLogDir() + g.fileNoExt + ".timeline.svg"
+}
+
+// getTimelineTickStep returns the interval between the ticks of the time axis
+// i.e., 1, 2 or 5 times a power of 10 seconds, such that there are at most 10
+// ticks.
+func getTimelineTickStep(total time.Duration) time.Duration {
+	step := time.Millisecond
+	for {
+		for _, multiple := range []time.Duration{1, 2, 5} {
+			if total <= 10*multiple*step {
+				return multiple * step
+			}
+		}
+		step *= 10
+	}
+}
+
+// timelineRow is a row of the timeline i.e., either a component heading or a
+// plugin.
+type timelineRow struct {
+	label     string
+	component bool
+	pInfo     Plugin
+}
+
+// getTimelineRows returns the plugins grouped by their components. The
+// components and the plugins within them are ordered by their start times,
+// while the plugins that didn't run are at the end.
+func getTimelineRows(pluginsInfo Plugins) []timelineRow {
+	sorted := append(Plugins{}, pluginsInfo...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iStart, jStart := sorted[i].StartTime, sorted[j].StartTime
+		if iStart.IsZero() != jStart.IsZero() {
+			return jStart.IsZero()
+		}
+		if !iStart.Equal(jStart) {
+			return iStart.Before(jStart)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	components := []string{}
+	componentPlugins := map[string]Plugins{}
+	for _, pInfo := range sorted {
+		component := path.Dir(pInfo.Name)
+		if _, ok := componentPlugins[component]; !ok {
+			components = append(components, component)
+		}
+		componentPlugins[component] = append(componentPlugins[component], pInfo)
+	}
+	rows := []timelineRow{}
+	for _, component := range components {
+		rows = append(rows, timelineRow{label: component, component: true})
+		for _, pInfo := range componentPlugins[component] {
+			rows = append(rows, timelineRow{label: path.Base(pInfo.Name), pInfo: pInfo})
+		}
+	}
+	return rows
+}
+
+// generateTimeline returns the timeline of the run as a self-contained SVG
+// image, having a bar per plugin from its start to its end colored as per its
+// status.
+func generateTimeline(result RunStatus) string {
+	pluginsInfo := getRunPlugins(result)
+	var start, end time.Time
+	for _, pInfo := range pluginsInfo {
+		if _, ran := getPluginDuration(pInfo); !ran {
+			continue
+		}
+		if start.IsZero() || pInfo.StartTime.Before(start) {
+			start = pInfo.StartTime
+		}
+		if pInfo.EndTime.After(end) {
+			end = pInfo.EndTime
+		}
+	}
+	total := end.Sub(start)
+	if total <= 0 {
+		total = time.Millisecond
+	}
+
+	// INFO: The plugins are indented under their components, and the plugins
+	// 	that didn't run are labelled with their status.
+	rows := getTimelineRows(pluginsInfo)
+	labelWidth := 0
+	for rIdx, row := range rows {
+		indent := 0
+		if !row.component {
+			indent = 4
+			if _, ran := getPluginDuration(row.pInfo); !ran {
+				rows[rIdx].label += " (" + row.pInfo.Status + ")"
+			}
+		}
+		if width := (indent + len(rows[rIdx].label) + 2) * timelineCharWidth; width > labelWidth {
+			labelWidth = width
+		}
+	}
+	chartX := timelineMargin + labelWidth
+	chartY := timelineMargin + 2*timelineRowHeight
+	width := chartX + timelineChartWidth + 4*timelineMargin
+	height := chartY + (len(rows)+2)*timelineRowHeight + timelineMargin
+	getX := func(t time.Time) float64 {
+		return float64(chartX) + float64(t.Sub(start))*timelineChartWidth/float64(total)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" `+
+		`font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	title := strings.TrimSpace(result.Type + " plugins " + result.RunID)
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="16" font-weight="bold">%s</text>`+"\n",
+		timelineMargin, timelineMargin+timelineRowHeight/2+4, html.EscapeString(title))
+
+	// INFO: Draw the time axis with its ticks as vertical grid lines.
+	step := getTimelineTickStep(total)
+	for tick := time.Duration(0); tick <= total; tick += step {
+		x := getX(start.Add(tick))
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="lightgrey"/>`+"\n",
+			x, chartY-4, x, chartY+len(rows)*timelineRowHeight)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n",
+			x, chartY-8, tick)
+	}
+
+	for rIdx, row := range rows {
+		y := chartY + rIdx*timelineRowHeight
+		if row.component {
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="whitesmoke"/>`+"\n",
+				timelineMargin, y, width-2*timelineMargin, timelineRowHeight)
+			fmt.Fprintf(&buf, `<text x="%d" y="%d" font-weight="bold">%s</text>`+"\n",
+				timelineMargin+4, y+timelineRowHeight-6, html.EscapeString(row.label))
+			continue
+		}
+		duration, ran := getPluginDuration(row.pInfo)
+		fmt.Fprintf(&buf, `<text x="%d" y="%d">%s</text>`+"\n",
+			timelineMargin+4*timelineCharWidth, y+timelineRowHeight-6, html.EscapeString(row.label))
+		if !ran {
+			continue
+		}
+		x := getX(row.pInfo.StartTime)
+		barWidth := math.Max(getX(row.pInfo.EndTime)-x, 1)
+		tooltip := fmt.Sprintf("%s: %s, started at %s, took %s", row.pInfo.Name,
+			row.pInfo.Status, row.pInfo.StartTime.Sub(start).Round(time.Millisecond),
+			duration.Round(time.Millisecond))
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s" stroke="black">`+
+			`<title>%s</title></rect>`+"\n", x, y+3, barWidth, timelineRowHeight-6,
+			getStatusColor(row.pInfo.Status), html.EscapeString(tooltip))
+	}
+
+	// INFO: Draw the legend of the status colors below the timeline.
+	y := chartY + (len(rows)+1)*timelineRowHeight
+	x := chartX
+	for _, status := range []string{dStatusOk, dStatusFail, dStatusFailIgnored, dStatusSkip} {
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="12" height="12" fill="%s" stroke="black"/>`+"\n",
+			x, y-10, getStatusColor(status))
+		fmt.Fprintf(&buf, `<text x="%d" y="%d">%s</text>`+"\n", x+16, y, status)
+		x += (len(status) + 5) * timelineCharWidth
+	}
+	buf.WriteString("</svg>\n")
+	return buf.String()
+}
+
+// saveRunTimeline saves the timeline image of the run next to its graph
+// image.
+//
+//	NOTE: Failing to save the timeline doesn't fail the run.
+func saveRunTimeline(result *RunStatus) {
+	if g.fileNoExt == "" || len(getRunPlugins(*result)) == 0 {
+		return
+	}
+	timelineFile := getTimelinePath()
+	if err := os.WriteFile(timelineFile, []byte(generateTimeline(*result)), 0644); err != nil {
+		logger.Warning.Printf("Failed to save the timeline in %s. Error: %s",
+			timelineFile, err.Error())
+		return
+	}
+	logger.Info.Printf("The timeline of the run is saved in %s.", timelineFile)
+}
+
+// Timeline generates the timeline image of the run result (i.e., the json or
+// yaml output of the run command) in the timeline file, and returns the file.
+// When the timeline file is not specified, the timeline image is generated
+// next to the run result as "<result>.timeline.svg".
+func Timeline(resultFile, timelineFile string) (string, error) {
+	logger.Debug.Printf("Entering Timeline(%s, %s)...", resultFile, timelineFile)
+	defer logger.Debug.Println("Exiting Timeline")
+
+	result, err := getPluginsInfoFromJSONStrOrFile(resultFile)
+	if err != nil {
+		return timelineFile, err
+	}
+	if timelineFile == "" {
+		timelineFile = strings.TrimSuffix(resultFile, filepath.Ext(resultFile)) + ".timeline.svg"
+	}
+	if err = os.WriteFile(timelineFile, []byte(generateTimeline(result)), 0644); err != nil {
+		return timelineFile, logger.ConsoleError.PrintNReturnError(
+			"Failed to write the timeline in %s. Error: %s", timelineFile, err.Error())
+	}
+	logger.ConsoleInfo.Printf("The timeline of the run is in %s", timelineFile)
+	return timelineFile, nil
+}
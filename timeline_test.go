@@ -0,0 +1,136 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_getTimelineTickStep(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		total time.Duration
+		want  time.Duration
+	}{
+		{total: 5 * time.Millisecond, want: time.Millisecond},
+		{total: 15 * time.Millisecond, want: 2 * time.Millisecond},
+		{total: 3 * time.Second, want: 500 * time.Millisecond},
+		{total: 10 * time.Second, want: time.Second},
+		{total: 95 * time.Second, want: 10 * time.Second},
+		{total: 10 * time.Minute, want: 100 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := getTimelineTickStep(tt.total); got != tt.want {
+			t.Errorf("getTimelineTickStep(%s) = %s, want %s", tt.total, got, tt.want)
+		}
+	}
+}
+
+func Test_getTimelineRows(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pluginsInfo := Plugins{
+		{Name: "A/a1.pre", StartTime: start.Add(2 * time.Second)},
+		{Name: "B/b.pre", StartTime: start.Add(time.Second)},
+		{Name: "A/a2.pre", StartTime: start},
+		{Name: "C/c.pre"},
+		{Name: "B/b.post", StartTime: start.Add(3 * time.Second)},
+	}
+	want := []string{"A", "a2.pre", "a1.pre", "B", "b.pre", "b.post", "C", "c.pre"}
+	got := []string{}
+	for _, row := range getTimelineRows(pluginsInfo) {
+		got = append(got, row.label)
+		if row.component != (row.pInfo.Name == "") {
+			t.Errorf("getTimelineRows() row %+v, want either a component or a plugin", row)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getTimelineRows() = %v, want %v", got, want)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	resultFile := filepath.Join(dir, "result.json")
+	bytes, err := json.Marshal(RunStatus{Type: "pre", RunID: "run-1", Phases: []RunStatus{
+		{Type: "pre", Plugins: Plugins{
+			{Name: "A/a<1>.pre", Status: dStatusOk, StartTime: start, EndTime: start.Add(time.Second)},
+			{Name: "B/b.pre", Status: dStatusFail, StartTime: start.Add(time.Second),
+				EndTime: start.Add(3 * time.Second)},
+		}},
+		{Type: "post", Plugins: Plugins{
+			{Name: "C/c.post", Status: dStatusSkip},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err = os.WriteFile(resultFile, bytes, 0644); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		timelineFile string
+		want         string
+	}{
+		{name: "Next to the run result", want: filepath.Join(dir, "result.timeline.svg")},
+		{name: "Specified file", timelineFile: filepath.Join(dir, "run.svg"),
+			want: filepath.Join(dir, "run.svg")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Timeline(resultFile, tt.timelineFile)
+			if err != nil || got != tt.want {
+				t.Fatalf("Timeline() = %s, %v, want %s", got, err, tt.want)
+			}
+			contents, err := os.ReadFile(got)
+			if err != nil {
+				t.Fatalf("os.ReadFile() error = %v", err)
+			}
+			// INFO: The timeline should be a well-formed XML document.
+			decoder := xml.NewDecoder(strings.NewReader(string(contents)))
+			for {
+				if _, err = decoder.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("Timeline() generated invalid SVG. Error: %v", err)
+				}
+			}
+			for _, s := range []string{
+				"a&lt;1&gt;.pre",
+				`fill="` + getStatusColor(dStatusOk) + `"`,
+				`fill="` + getStatusColor(dStatusFail) + `"`,
+				"c.post (" + dStatusSkip + ")",
+			} {
+				if !strings.Contains(string(contents), s) {
+					t.Errorf("Timeline() file doesn't contain %s", s)
+				}
+			}
+		})
+	}
+	if _, err = Timeline(filepath.Join(dir, "missing.json"), ""); err == nil {
+		t.Errorf("Timeline() of a missing run result, want error")
+	}
+}
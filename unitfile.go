@@ -0,0 +1,372 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm unitfile is used for parsing the plugin unit files.
+package pm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+)
+
+// unitFileIssue is an issue found while parsing a plugin unit file.
+type unitFileIssue struct {
+	// File is the plugin file (or its drop-in file) having the issue.
+	File string
+	// Line is the line number (starting from 1) of the issue.
+	Line    int
+	Message string
+}
+
+// unitSectionKeys are the keys supported in each section of a sectioned
+// plugin unit file.
+//
+//	NOTE: Similar to systemd, the keys related to the plugin and its
+//	dependencies go into [Unit] section, the keys related to the execution go
+//	into [Exec] section, while the reverse dependencies go into [Install]
+//	section.
+var unitSectionKeys = map[string][]string{
+	"Unit":    {"Description", "Requires", "Tags"},
+	"Exec":    {"ExecStart", "FailureMode", "SkipOnIgnoredFailure", "TimeoutSec"},
+	"Install": {"RequiredBy", "Instances", "InstancesGlob", "InstancesFrom", "Disabled"},
+}
+
+// getUnitKeySection returns the section of a sectioned plugin unit file
+// supporting the key, and empty string for an unknown key.
+func getUnitKeySection(key string) string {
+	for section, keys := range unitSectionKeys {
+		if containsString(keys, key) {
+			return section
+		}
+	}
+	return ""
+}
+
+// unitParser parses the plugin unit files.
+type unitParser struct {
+	// file is the plugin file name used for reporting the location of the
+	// 	issues.
+	file string
+	// strict indicates to report unknown keys, duplicate keys and invalid
+	// 	values as issues, and to continue parsing rest of the file.
+	//  When not set, unknown keys of a flat plugin file and duplicate keys
+	//  are ignored, and parsing fails on an invalid value, or on an unknown
+	//  or misplaced key of a sectioned plugin file.
+	strict bool
+	// issues found while parsing in strict mode.
+	issues []unitFileIssue
+	// sources tracks the locations (i.e., "<file>:<line>") where each key
+	// 	was set, when it's not nil. A list key accumulates the locations of
+	// 	all its values.
+	sources map[string][]string
+}
+
+// parseUnitFile parses the plugin file contents.
+func parseUnitFile(fileContents string) (Plugin, error) {
+	up := unitParser{}
+	return up.parse(fileContents)
+}
+
+// fail reports an issue in strict mode, and returns an error otherwise.
+func (up *unitParser) fail(lineNo int, msg string, args ...interface{}) error {
+	if up.strict {
+		up.issues = append(up.issues, unitFileIssue{File: up.file, Line: lineNo, Message: fmt.Sprintf(msg, args...)})
+		return nil
+	}
+	return logger.ConsoleError.PrintNReturnError(up.location(lineNo)+msg, args...)
+}
+
+// warn reports an issue in strict mode, and displays a warning otherwise.
+func (up *unitParser) warn(lineNo int, msg string, args ...interface{}) {
+	if up.strict {
+		up.issues = append(up.issues, unitFileIssue{File: up.file, Line: lineNo, Message: fmt.Sprintf(msg, args...)})
+		return
+	}
+	logger.ConsoleWarning.Printf(up.location(lineNo)+msg, args...)
+}
+
+// location returns the "<file>:<line>: " prefix of the issue messages.
+func (up *unitParser) location(lineNo int) string {
+	if up.file == "" {
+		return ""
+	}
+	if lineNo == 0 {
+		return up.file + ": "
+	}
+	return fmt.Sprintf("%s:%d: ", up.file, lineNo)
+}
+
+// setSource records the location where the key was set. When accumulate is
+// set, the location is added to the previous locations of the key.
+func (up *unitParser) setSource(key string, lineNo int, accumulate bool) {
+	if up.sources == nil {
+		return
+	}
+	loc := up.file
+	if lineNo != 0 {
+		loc = fmt.Sprintf("%s:%d", up.file, lineNo)
+	}
+	if accumulate {
+		up.sources[key] = append(up.sources[key], loc)
+		return
+	}
+	up.sources[key] = []string{loc}
+}
+
+// parse parses the plugin file contents.
+func (up *unitParser) parse(fileContents string) (Plugin, error) {
+	return up.parseOnto(Plugin{}, fileContents)
+}
+
+// parseOnto parses the plugin file contents over the specified plugin info,
+// i.e., the keys set in the file override the values of the plugin info,
+// while the list keys get accumulated. It's used for applying the drop-in
+// files over the plugin file.
+func (up *unitParser) parseOnto(pluginInfo Plugin, fileContents string) (Plugin, error) {
+	logger.Debug.Println("Entering parseUnitFile")
+	defer logger.Debug.Println("Exiting parseUnitFile")
+
+	if len(fileContents) == 0 {
+		return pluginInfo, nil
+	}
+	// keyLines tracks the line number where a key was set.
+	keyLines := map[string]int{}
+	// section is the current section of a sectioned plugin file. It's empty
+	// 	for the keys of a flat plugin file (i.e., without any sections).
+	section := ""
+	for _, ul := range getUnitLines(fileContents) {
+		lineNo := ul.lineNo
+		line := ul.line
+		logger.Debug.Println("line...", line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := unitSectionKeys[section]; !ok {
+				up.warn(lineNo, "Unknown section '[%s]'.", section)
+			}
+			continue
+		}
+
+		fields := strings.Split(line, "=")
+		if len(fields) == 0 {
+			continue
+		}
+		key := strings.TrimSpace(fields[0])
+		val := strings.TrimSpace(strings.Join(fields[1:], "="))
+		if len(fields) == 1 {
+			if up.strict {
+				up.fail(lineNo, "Invalid line '%s', expected 'Key=Value'.", line)
+			}
+			logger.Debug.Printf("Non-standard line found: %s", line)
+			continue
+		}
+		if section != "" {
+			// INFO: A plugin key in a section it doesn't belong to fails the
+			// 	parsing, as otherwise the plugin would run without it (Ex: a
+			// 	plugin without ExecStart passes without running anything).
+			if keySection := getUnitKeySection(key); keySection != "" && keySection != section {
+				if err := up.fail(lineNo, "Key '%s' must be in [%s] section, not in [%s] section.",
+					key, keySection, section); err != nil {
+					return pluginInfo, err
+				}
+				continue
+			}
+			if _, ok := unitSectionKeys[section]; !ok {
+				// Other keys of an unknown section are ignored, as the
+				// 	section itself is reported.
+				continue
+			}
+			if getUnitKeySection(key) == "" {
+				if err := up.fail(lineNo, "Unknown key '%s' in [%s] section.", key, section); err != nil {
+					return pluginInfo, err
+				}
+				continue
+			}
+		}
+		// INFO: List keys like Requires and RequiredBy can be repeated, and
+		// 	their values get accumulated.
+		if prevLineNo, ok := keyLines[key]; ok && up.strict && !isUnitListKey(key) {
+			up.fail(lineNo, "Duplicate key '%s' (previously set at line %d).", key, prevLineNo)
+		}
+		keyLines[key] = lineNo
+		switch key {
+		case "Description":
+			pluginInfo.Description = val
+			up.setSource(key, lineNo, false)
+			break
+		case "ExecStart":
+			pluginInfo.ExecStart = val
+			up.setSource(key, lineNo, false)
+			break
+		case "RequiredBy":
+			pluginInfo.RequiredBy = appendUnitList(pluginInfo.RequiredBy, val)
+			up.setSource(key, lineNo, val != "")
+			break
+		case "Requires":
+			pluginInfo.Requires = appendUnitList(pluginInfo.Requires, val)
+			up.setSource(key, lineNo, val != "")
+			break
+		case "Tags":
+			pluginInfo.Tags = appendUnitList(pluginInfo.Tags, val)
+			up.setSource(key, lineNo, val != "")
+			break
+		case "Instances":
+			pluginInfo.Instances = appendUnitList(pluginInfo.Instances, val)
+			up.setSource(key, lineNo, val != "")
+			break
+		case "InstancesGlob":
+			pluginInfo.InstancesGlob = val
+			up.setSource(key, lineNo, false)
+			break
+		case "InstancesFrom":
+			pluginInfo.InstancesFrom = val
+			up.setSource(key, lineNo, false)
+			break
+		case "FailureMode":
+			switch val {
+			case "", failureModeIgnore, failureModeWarn, failureModeFatal:
+				pluginInfo.FailureMode = val
+				up.setSource(key, lineNo, false)
+			default:
+				if err := up.fail(lineNo,
+					"Invalid FailureMode '%s'. Supported values are '%s', '%s' and '%s'.",
+					val, failureModeIgnore, failureModeWarn, failureModeFatal); err != nil {
+					return pluginInfo, err
+				}
+			}
+			break
+		case "SkipOnIgnoredFailure":
+			bVal, err := parseUnitBool(val)
+			if err != nil {
+				if err = up.fail(lineNo, "Invalid SkipOnIgnoredFailure '%s'. %s", val, err.Error()); err != nil {
+					return pluginInfo, err
+				}
+				break
+			}
+			pluginInfo.SkipOnIgnoredFailure = bVal
+			up.setSource(key, lineNo, false)
+			break
+		case "TimeoutSec":
+			// INFO: An empty value resets the timeout.
+			timeout := 0
+			if val != "" {
+				var err error
+				timeout, err = strconv.Atoi(val)
+				if err != nil || timeout < 0 {
+					if err = up.fail(lineNo, "Invalid TimeoutSec '%s'. Expected a non-negative number of seconds.",
+						val); err != nil {
+						return pluginInfo, err
+					}
+					break
+				}
+			}
+			pluginInfo.TimeoutSec = timeout
+			up.setSource(key, lineNo, false)
+			break
+		case "Disabled":
+			bVal, err := parseUnitBool(val)
+			if err != nil {
+				if err = up.fail(lineNo, "Invalid Disabled '%s'. %s", val, err.Error()); err != nil {
+					return pluginInfo, err
+				}
+				break
+			}
+			pluginInfo.Disabled = bVal
+			up.setSource(key, lineNo, false)
+			break
+		default:
+			if up.strict {
+				up.fail(lineNo, "Unknown key '%s'.", key)
+			}
+			logger.Debug.Printf("Non-standard line found: %s", line)
+			break
+		}
+	}
+
+	return pluginInfo, nil
+}
+
+// unitLine is a logical line of a plugin unit file, i.e., the physical lines
+// joined by backslash line continuations.
+type unitLine struct {
+	// lineNo is the line number (starting from 1) where the logical line
+	// 	starts.
+	lineNo int
+	line   string
+}
+
+// getUnitLines returns the logical lines of the plugin file contents by
+// joining the lines ending with a backslash with the next line, and skipping
+// the empty and comment lines.
+//
+//	NOTE: Similar to systemd, comment lines in between the continuation lines
+//	are skipped, and the continuation lines are joined with a space.
+func getUnitLines(fileContents string) []unitLine {
+	unitLines := []unitLine{}
+	cur := unitLine{}
+	continued := false
+	lines := strings.Split(fileContents, "\n")
+	for l := range lines {
+		line := strings.TrimSpace(lines[l])
+		if strings.HasPrefix(line, "#") {
+			// No need to parse comments.
+			logger.Debug.Println("Skipping comment line...", line)
+			continue
+		}
+		if !continued {
+			if len(line) == 0 {
+				continue
+			}
+			cur = unitLine{lineNo: l + 1}
+		}
+		continued = strings.HasSuffix(line, "\\")
+		if continued {
+			line = strings.TrimSpace(strings.TrimSuffix(line, "\\"))
+		}
+		if len(cur.line) != 0 && len(line) != 0 {
+			cur.line += " "
+		}
+		cur.line += line
+		if !continued {
+			unitLines = append(unitLines, cur)
+		}
+	}
+	if continued && len(cur.line) != 0 {
+		// Last line ends with a backslash.
+		unitLines = append(unitLines, cur)
+	}
+	return unitLines
+}
+
+// isUnitListKey returns whether the key is a list key whose values accumulate
+// when the key is repeated.
+func isUnitListKey(key string) bool {
+	switch key {
+	case "RequiredBy", "Requires", "Tags", "Instances":
+		return true
+	}
+	return false
+}
+
+// appendUnitList appends the whitespace separated values of a list key to
+// the list. Similar to systemd, an empty value resets the list.
+func appendUnitList(list []string, val string) []string {
+	if val == "" {
+		return nil
+	}
+	return append(list, strings.Fields(val)...)
+}
+
+// parseUnitBool parses the boolean value of a plugin file key. Similar to
+// systemd, "yes", "true", "on" and "1" are treated as true, while "no",
+// "false", "off" and "0" are treated as false.
+func parseUnitBool(val string) (bool, error) {
+	switch strings.ToLower(val) {
+	case "yes", "true", "on", "1":
+		return true, nil
+	case "no", "false", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("Expected a boolean value (yes/no, true/false, on/off, 1/0)")
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func Test_unitParser_strict(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name         string
+		fileContents string
+		pluginInfo   Plugin
+		issues       []unitFileIssue
+	}{
+		{
+			name: "No issues",
+			fileContents: `
+Description=Applying "A" settings
+ExecStart=/bin/echo "Running A...!"
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"A\" settings",
+				ExecStart:   "/bin/echo \"Running A...!\"",
+			},
+		},
+		{
+			name: "Unknown keys, invalid lines and invalid values",
+			fileContents: `Description=Applying "A" settings
+Desc=Typo in key
+ExecStart
+FailureMode=never
+SkipOnIgnoredFailure=maybe
+TimeoutSec=-1
+Disabled=maybe
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"A\" settings",
+			},
+			issues: []unitFileIssue{
+				{Line: 2, Message: "Unknown key 'Desc'."},
+				{Line: 3, Message: "Invalid line 'ExecStart', expected 'Key=Value'."},
+				{Line: 4, Message: "Invalid FailureMode 'never'. Supported values are 'ignore', 'warn' and 'fatal'."},
+				{Line: 5, Message: "Invalid SkipOnIgnoredFailure 'maybe'. Expected a boolean value (yes/no, true/false, on/off, 1/0)"},
+				{Line: 6, Message: "Invalid TimeoutSec '-1'. Expected a non-negative number of seconds."},
+				{Line: 7, Message: "Invalid Disabled 'maybe'. Expected a boolean value (yes/no, true/false, on/off, 1/0)"},
+			},
+		},
+		{
+			name: "Repeated list keys and continuation lines",
+			fileContents: `Description=Applying \
+  "A" settings
+Requires=B/b.test
+Requires=C/c.test
+ExecStart=/bin/echo "Running A...!"
+Description=Duplicate \
+  description
+`,
+			pluginInfo: Plugin{
+				Description: "Duplicate description",
+				ExecStart:   "/bin/echo \"Running A...!\"",
+				Requires:    []string{"B/b.test", "C/c.test"},
+			},
+			issues: []unitFileIssue{
+				{Line: 6, Message: "Duplicate key 'Description' (previously set at line 1)."},
+			},
+		},
+		{
+			name: "Sectioned plugin file",
+			fileContents: `# Sectioned plugin file.
+[Unit]
+Description=Applying "A" settings
+Requires=B/b.test
+
+[Exec]
+ExecStart=/bin/echo "Running A...!"
+FailureMode=warn
+TimeoutSec=30
+
+[Install]
+RequiredBy=C/c.test
+Disabled=yes
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"A\" settings",
+				ExecStart:   "/bin/echo \"Running A...!\"",
+				FailureMode: "warn",
+				TimeoutSec:  30,
+				Requires:    []string{"B/b.test"},
+				RequiredBy:  []string{"C/c.test"},
+				Disabled:    true,
+			},
+		},
+		{
+			name: "Sectioned plugin file with unknown sections and misplaced keys",
+			fileContents: `[Unit]
+Description=Applying "A" settings
+ExecStart=/bin/echo "Running A...!"
+Foo=bar
+[Service]
+User=root
+ExecStart=/bin/echo "Running A...!"
+[Install]
+RequiredBy=C/c.test
+`,
+			pluginInfo: Plugin{
+				Description: "Applying \"A\" settings",
+				RequiredBy:  []string{"C/c.test"},
+			},
+			issues: []unitFileIssue{
+				{Line: 3, Message: "Key 'ExecStart' must be in [Exec] section, not in [Unit] section."},
+				{Line: 4, Message: "Unknown key 'Foo' in [Unit] section."},
+				{Line: 5, Message: "Unknown section '[Service]'."},
+				{Line: 7, Message: "Key 'ExecStart' must be in [Exec] section, not in [Service] section."},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up := unitParser{strict: true}
+			res, err := up.parse(tt.fileContents)
+			if err != nil {
+				t.Errorf("parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(res, tt.pluginInfo) {
+				t.Errorf("parse() = %+v, want %+v", res, tt.pluginInfo)
+			}
+			if !reflect.DeepEqual(up.issues, tt.issues) {
+				t.Errorf("parse() issues = %+v, want %+v", up.issues, tt.issues)
+			}
+		})
+	}
+}
+
+func Test_unitParser_location(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	up := unitParser{file: "A/a.test"}
+	_, err := up.parse("[Exec]\nFailureMode=never\n")
+	want := "A/a.test:2: Invalid FailureMode 'never'. Supported values are 'ignore', 'warn' and 'fatal'."
+	if err == nil || err.Error() != want {
+		t.Errorf("parse() error = %v, want %v", err, want)
+	}
+}
+
+func Test_getPluginsInfoFromLibrary_misplacedKey(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	library := createTestLibrary(t, map[string]string{
+		"A/a.test": "[Unit]\nDescription=A\nExecStart=/bin/false\n",
+	})
+	_, err := getPluginsInfoFromLibrary("test", library)
+	want := "A/a.test:3: Key 'ExecStart' must be in [Exec] section, not in [Unit] section."
+	if err == nil || err.Error() != want {
+		t.Errorf("getPluginsInfoFromLibrary() error = %v, want %v", err, want)
+	}
+}
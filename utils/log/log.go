@@ -235,6 +235,15 @@ func IsFileLogger() bool {
 	return false
 }
 
+// GetLogFilePath returns the path of the log file being written to, and empty
+// string if file log is not initialized (i.e., syslog is used).
+func GetLogFilePath() string {
+	if fh, ok := fileLogHandle.(*FileLogHandle); ok && fh.logFile != nil {
+		return fh.logFile.Name()
+	}
+	return ""
+}
+
 // initLogger sets logger for all supported log levels
 func initLogger(
 	traceHandle io.Writer,
@@ -0,0 +1,358 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+
+// Package pm validate is used for linting the plugins in a plugins library.
+package pm
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VeritasOS/plugin-manager/config"
+	logger "github.com/VeritasOS/plugin-manager/utils/log"
+	osutils "github.com/VeritasOS/plugin-manager/utils/os"
+)
+
+// ValidationIssue is an issue found while validating a plugin.
+type ValidationIssue struct {
+	// Type is the plugin type.
+	Type string
+	// Library is the plugins library of the plugin file. It's set only when
+	// 	multiple libraries are validated.
+	Library string `yaml:",omitempty" json:",omitempty"`
+	// File is the plugin file path relative to the plugins library.
+	File string
+	// Line is the line number of the issue in the plugin file. It's not set
+	// 	when the issue is not specific to a line.
+	Line    int `yaml:",omitempty" json:",omitempty"`
+	Message string
+}
+
+// String returns the issue in "<file>:<line>: <message>" format.
+func (vi ValidationIssue) String() string {
+	location := vi.File
+	if vi.Library != "" {
+		location = filepath.Join(vi.Library, vi.File)
+	}
+	if vi.Line != 0 {
+		location += ":" + strconv.Itoa(vi.Line)
+	}
+	return location + ": " + vi.Message
+}
+
+// ValidationReport is the result of validating the plugins library.
+type ValidationReport struct {
+	Library string
+	// Libraries are the validated plugins libraries with the highest
+	// 	precedence library first. It's set only when multiple libraries are
+	// 	validated.
+	Libraries []string `yaml:",omitempty" json:",omitempty"`
+	Types     []string
+	Issues    []ValidationIssue `yaml:",omitempty"`
+	Status    string
+}
+
+// ValidateOptions are optional parameters related to validate function.
+type ValidateOptions struct {
+	// Types of plugins to validate. When not specified, all plugin types
+	// 	present in the library are validated.
+	Types []string
+}
+
+// getLibraryPluginTypes returns the plugin types present in the library, i.e.,
+// the extensions of the plugin files in the component directories.
+//
+//	NOTE: Executable files (like scripts invoked by plugins) and the files
+//	that don't set any of the plugin keys (like README.md or notes.txt) are
+//	not plugin files, and hence are not considered.
+func getLibraryPluginTypes(library string) ([]string, error) {
+	types := []string{}
+	files, err := getLibraryFiles(library, config.GetLibraryDepth())
+	if err != nil {
+		return types, err
+	}
+	for _, file := range files {
+		fi, err := os.Stat(filepath.FromSlash(library + "/" + file))
+		pluginType := getPluginType(getPluginName(file))
+		if err != nil || fi.Mode()&0111 != 0 || pluginType == "" {
+			continue
+		}
+		if containsString(types, pluginType) || !isPluginFile(library, file) {
+			continue
+		}
+		types = append(types, pluginType)
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// isPluginFile returns whether the file in the library is a plugin file, i.e.,
+// either a plugin definition file, or a plugin unit file setting at least one
+// of the plugin keys.
+func isPluginFile(library, file string) bool {
+	if getPluginDefFormat(file) != "" {
+		return true
+	}
+	fileContents, err := readFile(filepath.FromSlash(library + "/" + file))
+	if err != nil {
+		return false
+	}
+	// INFO: The issues of the plugin file are not of interest here, and are
+	// 	reported while validating the plugins of its type.
+	up := unitParser{file: file, strict: true, sources: map[string][]string{}}
+	if _, err = up.parse(fileContents); err != nil {
+		return false
+	}
+	return len(up.sources) != 0
+}
+
+// ValidateLibrary validates the plugins in the library, and reports issues
+// like unknown or duplicate keys, empty description, missing executables,
+// dangling or cross-type dependencies and circular dependencies.
+func ValidateLibrary(library string, validateOptions ValidateOptions) (ValidationReport, error) {
+	return ValidateLibraries([]string{library}, validateOptions)
+}
+
+// ValidateLibraries validates the plugins in the libraries similar to
+// ValidateLibrary. The plugins in the libraries listed first override the
+// same-named plugins in the libraries listed later, and hence the overridden
+// plugins are not validated.
+func ValidateLibraries(libraries []string, validateOptions ValidateOptions) (ValidationReport, error) {
+	logger.Debug.Printf("Entering ValidateLibraries(%v, %+v)...", libraries, validateOptions)
+	defer logger.Debug.Println("Exiting ValidateLibraries")
+
+	report := ValidationReport{
+		Library: libraries[0],
+		Types:   validateOptions.Types,
+	}
+	if len(libraries) > 1 {
+		report.Libraries = libraries
+	}
+	existingLibraries := []string{}
+	for _, library := range libraries {
+		if _, err := os.Stat(library); os.IsNotExist(err) {
+			if len(libraries) == 1 {
+				report.Status = dStatusFail
+				return report, logger.ConsoleError.PrintNReturnError("Library '%s' doesn't exist. "+
+					"A valid plugins library path must be specified.", library)
+			}
+			logger.Warning.Printf("Skipping library %s as it doesn't exist.", library)
+			continue
+		}
+		existingLibraries = append(existingLibraries, library)
+	}
+	if len(report.Types) == 0 {
+		for _, library := range existingLibraries {
+			types, err := getLibraryPluginTypes(library)
+			if err != nil {
+				report.Status = dStatusFail
+				return report, err
+			}
+			for _, pluginType := range types {
+				if !containsString(report.Types, pluginType) {
+					report.Types = append(report.Types, pluginType)
+				}
+			}
+		}
+		sort.Strings(report.Types)
+	}
+
+	for _, pluginType := range report.Types {
+		issues, err := validatePluginType(pluginType, existingLibraries, len(libraries) > 1)
+		if err != nil {
+			report.Status = dStatusFail
+			return report, err
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+
+	report.Status = dStatusOk
+	if len(report.Issues) != 0 {
+		report.Status = dStatusFail
+	}
+	return report, nil
+}
+
+// validatePluginType validates the plugins of specified type in the libraries.
+// The withLibrary indicates to set the library of the issues.
+func validatePluginType(pluginType string, libraries []string, withLibrary bool) ([]ValidationIssue, error) {
+	issues := []ValidationIssue{}
+	envMap := osutils.EnvMap()
+	getEnvVal := func(name string) string {
+		return envMap[name]
+	}
+
+	var pluginsInfo Plugins
+	// pluginFileNames and pluginLibraries track the file defining each plugin
+	// 	and its library respectively.
+	pluginFileNames := map[string]string{}
+	pluginLibraries := map[string]string{}
+	newIssue := func(pName, file string, lineNo int, msg string) ValidationIssue {
+		issue := ValidationIssue{Type: pluginType, File: file, Line: lineNo, Message: msg}
+		if withLibrary {
+			issue.Library = pluginLibraries[pName]
+		}
+		return issue
+	}
+	for _, library := range libraries {
+		pluginFiles, err := getPluginFiles(pluginType, library)
+		if err != nil {
+			return issues, err
+		}
+		envMap["PM_LIBRARY"] = library
+		for _, file := range pluginFiles {
+			pName := getPluginName(file)
+			if prevLibrary, ok := pluginLibraries[pName]; ok && prevLibrary != library {
+				logger.Info.Printf("Plugin %s of %s library is overridden by %s library.",
+					pName, library, prevLibrary)
+				continue
+			}
+			if prevFile, ok := pluginFileNames[pName]; ok {
+				issues = append(issues, newIssue(pName, file, 0,
+					"Plugin '"+pName+"' is also defined in '"+prevFile+"'."))
+				continue
+			}
+			pluginFileNames[pName] = file
+			pluginLibraries[pName] = library
+			up := unitParser{strict: true}
+			pInfo, _, lerr := loadPlugin(library, config.GetPluginsOverrideLibrary(), file, &up)
+			if lerr != nil {
+				return issues, lerr
+			}
+			for _, ui := range up.issues {
+				issues = append(issues, newIssue(pName, ui.File, ui.Line, ui.Message))
+			}
+			if strings.TrimSpace(pInfo.Description) == "" {
+				issues = append(issues, newIssue(pName, file, 0, "Description is empty."))
+			}
+			hasInstances := len(pInfo.Instances) != 0 || pInfo.InstancesGlob != "" ||
+				pInfo.InstancesFrom != ""
+			if isPluginTemplate(pName) && !hasInstances {
+				issues = append(issues, newIssue(pName, file, 0,
+					"Template plugin has none of Instances, InstancesGlob or InstancesFrom."))
+			} else if !isPluginTemplate(pName) && hasInstances {
+				issues = append(issues, newIssue(pName, file, 0,
+					"Instances, InstancesGlob and InstancesFrom are supported only by template plugins."))
+			}
+			if pInfo.ExecStart != "" {
+				cmdStr := strings.Split(os.Expand(pInfo.ExecStart, getEnvVal), " ")[0]
+				if _, err := exec.LookPath(cmdStr); err != nil {
+					issues = append(issues, newIssue(pName, file, 0,
+						"ExecStart binary '"+cmdStr+"' doesn't exist or isn't executable."))
+				}
+			}
+			pluginsInfo = append(pluginsInfo, pInfo)
+		}
+	}
+
+	for _, pInfo := range pluginsInfo {
+		for _, rby := range pInfo.RequiredBy {
+			if isPluginRef(rby) {
+				issues = append(issues, newIssue(pInfo.Name, pluginFileNames[pInfo.Name], 0,
+					"Plugin reference '"+rby+"' is supported only in Requires."))
+			}
+		}
+		for _, rs := range pInfo.Requires {
+			if !isPluginRef(rs) {
+				continue
+			}
+			if _, pName, perr := parsePluginRef(rs); perr != nil {
+				issues = append(issues, newIssue(pInfo.Name, pluginFileNames[pInfo.Name], 0, perr.Error()))
+			} else if !isPluginPresent(libraries, pName) {
+				issues = append(issues, newIssue(pInfo.Name, pluginFileNames[pInfo.Name], 0,
+					"Plugin reference '"+rs+"' to '"+pName+"' which is not present."))
+			}
+		}
+	}
+
+	state, err := loadPluginsState()
+	if err != nil {
+		return issues, err
+	}
+	// INFO: The template plugins aren't instantiated while validating, as their
+	// 	instances could depend on the host. So, the dependencies on the
+	// 	instances are validated as the dependencies on their templates.
+	toTemplate := func(dependencies []string) []string {
+		deps := []string{}
+		for _, dep := range dependencies {
+			if tName := getTemplateName(dep); tName != "" && !isPluginRef(dep) {
+				if _, ok := pluginFileNames[tName]; ok {
+					dep = tName
+				}
+			}
+			if !containsString(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+		return deps
+	}
+	tPInfo := Plugins{}
+	for _, pInfo := range pluginsInfo {
+		pInfo.Requires = toTemplate(pInfo.Requires)
+		pInfo.RequiredBy = toTemplate(pInfo.RequiredBy)
+		tPInfo = append(tPInfo, pInfo)
+	}
+	nPInfo := normalizePluginsInfo(tPInfo)
+	for _, pInfo := range nPInfo {
+		if state.getState(pInfo.Name) == dStateMasked {
+			continue
+		}
+		for _, rs := range pInfo.Requires {
+			if state.getState(rs) == dStateMasked {
+				issues = append(issues, newIssue(pInfo.Name, pluginFileNames[pInfo.Name], 0,
+					"Dependency on masked plugin '"+rs+"'; the plugin would be skipped."))
+			}
+		}
+	}
+
+	// requires tracks the Requires specified in the plugin files to suggest
+	// 	the plugin references for cross-type dependencies.
+	requires := map[string]map[string]bool{}
+	for _, pInfo := range pluginsInfo {
+		requires[pInfo.Name] = map[string]bool{}
+		for _, rs := range pInfo.Requires {
+			requires[pInfo.Name][rs] = true
+		}
+	}
+	depErr := diagnoseDependencies(nPInfo, nil)
+	for _, m := range depErr.Missing {
+		msg := "Dangling dependency on '" + m.Requires + "' which is not present."
+		if refType := getPluginType(m.Requires); refType != pluginType {
+			msg = "Cross-type dependency on '" + m.Requires + "' of '" + refType + "' plugin type. "
+			if requires[m.Plugin][m.Requires] {
+				msg += "Use 'Requires=" + pluginRefPrefix + refType + ":" + m.Requires + "' to depend on it."
+			} else {
+				msg += "Use 'Requires=" + pluginRefPrefix + pluginType + ":" + m.Plugin +
+					"' in '" + m.Requires + "' instead."
+			}
+		}
+		issues = append(issues, newIssue(m.Plugin, pluginFileNames[m.Plugin], 0, msg))
+	}
+	for _, cycle := range depErr.Cycles {
+		issues = append(issues, newIssue(cycle[0], pluginFileNames[cycle[0]], 0,
+			"Circular dependency: "+strings.Join(cycle, " -> ")))
+	}
+	return issues, nil
+}
+
+// Validate validates the plugins libraries, and displays the issues found.
+func Validate(libraries []string, validateOptions ValidateOptions) (ValidationReport, error) {
+	report, err := ValidateLibraries(libraries, validateOptions)
+	if err != nil {
+		return report, err
+	}
+	for _, issue := range report.Issues {
+		logger.ConsoleError.Printf("%s", issue.String())
+	}
+	if report.Status != dStatusOk {
+		return report, logger.ConsoleError.PrintNReturnError(
+			"Validating %s plugins: %s (%d issues)",
+			strings.Join(report.Types, ", "), dStatusFail, len(report.Issues))
+	}
+	logger.ConsoleInfo.Printf("Validating %s plugins: %s",
+		strings.Join(report.Types, ", "), dStatusOk)
+	return report, nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2024 Veritas Technologies LLC. All rights reserved. IP63-2828-7171-04-15-9
+package pm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// createTestLibrary creates a plugins library with the specified files and
+// their contents, and returns the library path. The ".sh" files are created
+// as executables.
+func createTestLibrary(t *testing.T, files map[string]string) string {
+	library := t.TempDir() + string(os.PathSeparator)
+	for file, contents := range files {
+		filePath := filepath.FromSlash(library + file)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatalf("os.MkdirAll(%s) err=%s", filepath.Dir(filePath), err)
+		}
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(file, ".sh") || strings.HasSuffix(file, generatorSuffix) {
+			mode = 0755
+		}
+		if err := ioutil.WriteFile(filePath, []byte(contents), mode); err != nil {
+			t.Fatalf("ioutil.WriteFile(%s) err=%s", filePath, err)
+		}
+	}
+	return library
+}
+
+func TestValidateLibrary(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		types      []string
+		wantTypes  []string
+		wantIssues []ValidationIssue
+		wantStatus string
+	}{
+		{
+			name: "Valid plugins of all types",
+			files: map[string]string{
+				"A/a.test":  "Description=A\nExecStart=/bin/echo A\n",
+				"B/b.test":  "Description=B\nRequires=A/a.test\n",
+				"A/a.check": "Description=A check\n",
+			},
+			wantTypes:  []string{"check", "test"},
+			wantStatus: dStatusOk,
+		},
+		{
+			name: "Non-plugin files are not plugin types",
+			files: map[string]string{
+				"A/a.test":    "Description=A\nExecStart=/bin/echo A\n",
+				"A/README.md": "# A\n\nThe plugins of A.\n",
+				"A/notes.txt": "TODO=Add a check plugin\n",
+				"A/x.conf":    "[Service]\nUser=root\n",
+			},
+			wantTypes:  []string{"test"},
+			wantStatus: dStatusOk,
+		},
+		{
+			name: "Unknown & duplicate keys, empty description and missing binary",
+			files: map[string]string{
+				"A/a.test": "Description=A\nDescription=A again\nFoo=bar\nExecStart=/non/existent/binary -v\n",
+				"B/b.test": "ExecStart=${PM_LIBRARY}/B/b.sh\n",
+				"B/b.sh":   "#!/bin/sh\n",
+			},
+			types:     []string{"test"},
+			wantTypes: []string{"test"},
+			wantIssues: []ValidationIssue{
+				{Type: "test", File: "A/a.test", Line: 2, Message: "Duplicate key 'Description' (previously set at line 1)."},
+				{Type: "test", File: "A/a.test", Line: 3, Message: "Unknown key 'Foo'."},
+				{Type: "test", File: "A/a.test", Message: "ExecStart binary '/non/existent/binary' doesn't exist or isn't executable."},
+				{Type: "test", File: "B/b.test", Message: "Description is empty."},
+			},
+			wantStatus: dStatusFail,
+		},
+		{
+			name: "Dangling, cross-type and circular dependencies",
+			files: map[string]string{
+				"A/a.test":  "Description=A\nRequires=B/b.test\n",
+				"B/b.test":  "Description=B\nRequires=A/a.test\n",
+				"C/c.test":  "Description=C\nRequires=X/x.test\nRequiredBy=A/a.check\n",
+				"A/a.check": "Description=A check\n",
+			},
+			types:     []string{"test"},
+			wantTypes: []string{"test"},
+			wantIssues: []ValidationIssue{
+				{Type: "test", File: "C/c.test", Message: "Dangling dependency on 'X/x.test' which is not present."},
+				{Type: "test", File: "C/c.test", Message: "Cross-type dependency on 'A/a.check' of 'check' plugin type. " +
+					"Use 'Requires=@test:C/c.test' in 'A/a.check' instead."},
+				{Type: "test", File: "A/a.test", Message: "Circular dependency: A/a.test -> B/b.test -> A/a.test"},
+			},
+			wantStatus: dStatusFail,
+		},
+		{
+			name: "Plugin references",
+			files: map[string]string{
+				"A/a.test":  "Description=A\nRequires=@check:A/a.check @check:X/x.check @check:B/b.test\n",
+				"B/b.test":  "Description=B\nRequiredBy=@check:A/a.check\n",
+				"A/a.check": "Description=A check\n",
+			},
+			types:     []string{"test"},
+			wantTypes: []string{"test"},
+			wantIssues: []ValidationIssue{
+				{Type: "test", File: "A/a.test", Message: "Plugin reference '@check:X/x.check' to 'X/x.check' which is not present."},
+				{Type: "test", File: "A/a.test", Message: "Invalid plugin reference '@check:B/b.test'. " +
+					"Expected format is '@<type>:<component>/<plugin-file>.<type>'."},
+				{Type: "test", File: "B/b.test", Message: "Plugin reference '@check:A/a.check' is supported only in Requires."},
+			},
+			wantStatus: dStatusFail,
+		},
+		{
+			name: "Plugin definition files",
+			files: map[string]string{
+				"A/a.test.yaml": "description: A\nrequires:\n  - B/b.test\nfoo: bar\n",
+				"B/b.test.json": `{"Description": "B"}`,
+				"B/b.test":      "Description=B\n",
+			},
+			wantTypes: []string{"test"},
+			wantIssues: []ValidationIssue{
+				{Type: "test", File: "A/a.test.yaml", Message: "Plugin definition is not in expected format. " +
+					"Error: yaml: unmarshal errors:\n  line 4: field foo not found in type pm.Plugin"},
+				{Type: "test", File: "B/b.test.json", Message: "Plugin 'B/b.test' is also defined in 'B/b.test'."},
+			},
+			wantStatus: dStatusFail,
+		},
+		{
+			name: "Template plugins",
+			files: map[string]string{
+				"A/a@.test": "Description=A %i\n[Install]\nInstances=x y\n",
+				"B/b@.test": "Description=B %i\nRequires=A/a@%i.test\n",
+				"C/c.test":  "Description=C\nRequires=A/a@x.test B/b@.test\n[Install]\nInstances=z\n",
+			},
+			wantTypes: []string{"test"},
+			wantIssues: []ValidationIssue{
+				{Type: "test", File: "B/b@.test",
+					Message: "Template plugin has none of Instances, InstancesGlob or InstancesFrom."},
+				{Type: "test", File: "C/c.test",
+					Message: "Instances, InstancesGlob and InstancesFrom are supported only by template plugins."},
+			},
+			wantStatus: dStatusFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := createTestLibrary(t, tt.files)
+			got, err := ValidateLibrary(library, ValidateOptions{Types: tt.types})
+			if err != nil {
+				t.Fatalf("ValidateLibrary() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Types, tt.wantTypes) {
+				t.Errorf("ValidateLibrary() Types = %v, want %v", got.Types, tt.wantTypes)
+			}
+			if !reflect.DeepEqual(got.Issues, tt.wantIssues) {
+				t.Errorf("ValidateLibrary() Issues = %+v, want %+v", got.Issues, tt.wantIssues)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("ValidateLibrary() Status = %v, want %v", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestValidateLibraries(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "RUNNING" {
+		t.Skip("Not applicable while running integration tests.")
+		return
+	}
+
+	siteLibrary := createTestLibrary(t, map[string]string{
+		"A/a.test": "Description=Site A\nRequires=B/b.test\n",
+	})
+	baseLibrary := createTestLibrary(t, map[string]string{
+		"A/a.test":  "Foo=Overridden plugin is not validated\n",
+		"B/b.test":  "Description=Base B\nRequires=X/x.test\n",
+		"B/b.check": "Description=Base B check\n",
+	})
+	got, err := ValidateLibraries([]string{siteLibrary, baseLibrary}, ValidateOptions{})
+	if err != nil {
+		t.Fatalf("ValidateLibraries() error = %v", err)
+	}
+	want := ValidationReport{
+		Library:   siteLibrary,
+		Libraries: []string{siteLibrary, baseLibrary},
+		Types:     []string{"check", "test"},
+		Issues: []ValidationIssue{
+			{Type: "test", Library: baseLibrary, File: "B/b.test",
+				Message: "Dangling dependency on 'X/x.test' which is not present."},
+		},
+		Status: dStatusFail,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateLibraries() = %+v, want %+v", got, want)
+	}
+	if got.Issues[0].String() != filepath.Join(baseLibrary, "B/b.test")+
+		": Dangling dependency on 'X/x.test' which is not present." {
+		t.Errorf("String() = %s", got.Issues[0].String())
+	}
+}